@@ -0,0 +1,134 @@
+package couchdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Attachment describes a file stored alongside a document. Documents fetched from couchdb
+// carry stubs, which only contain the metadata; keeping them when updating a document
+// keeps the attachments.
+type Attachment struct {
+	ContentType string `json:"content_type,omitempty"`
+	Length      int64  `json:"length,omitempty"`
+	Digest      string `json:"digest,omitempty"`
+	RevPos      int    `json:"revpos,omitempty"`
+	Stub        bool   `json:"stub,omitempty"`
+	// Follows marks attachments sent as separate parts of a multipart/related request
+	Follows bool `json:"follows,omitempty"`
+	// Data holds the attachment body when it is inlined into the document
+	Data []byte `json:"data,omitempty"`
+}
+
+// AttachmentReader streams an attachment body from couchdb. It must be closed by the caller.
+type AttachmentReader struct {
+	io.ReadCloser
+	ContentType string
+	// Length is -1 if couchdb did not report the size
+	Length int64
+	Digest string
+}
+
+type revResponse struct {
+	ID  string `json:"id"`
+	Rev string `json:"rev"`
+}
+
+func attachmentPath(docID, name string) string {
+	return fmt.Sprintf("/%s/%s", docID, url.PathEscape(name))
+}
+
+// PutAttachment stores body as an attachment of the document identified by docID, returning
+// the new document revision. PUT /{db}/{docid}/{attname}
+// The body is streamed to couchdb; pass an empty rev to create a new document.
+func (d *Database) PutAttachment(ctx context.Context, docID, rev, name, contentType string, body io.Reader) (string, error) {
+	req, err := http.NewRequest("PUT", attachmentPath(docID, name), body)
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", contentType)
+	if rev != "" {
+		values := req.URL.Query()
+		values.Set("rev", rev)
+		req.URL.RawQuery = values.Encode()
+	}
+
+	resp, err := d.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusAccepted {
+		return "", newError(req, resp, nil)
+	}
+	res := revResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return "", err
+	}
+	return res.Rev, nil
+}
+
+// GetAttachment streams an attachment of the latest document revision. GET /{db}/{docid}/{attname}
+//
+//   att, err := db.GetAttachment(ctx, "invoice:1", "invoice.pdf")
+//   if err != nil {
+//     return err
+//   }
+//   defer att.Close()
+//   io.Copy(w, att)
+func (d *Database) GetAttachment(ctx context.Context, docID, name string) (*AttachmentReader, error) {
+	req, err := http.NewRequest("GET", attachmentPath(docID, name), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	resp, err := d.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, newError(req, resp, nil)
+	}
+	att := &AttachmentReader{
+		ReadCloser:  resp.Body,
+		ContentType: resp.Header.Get("Content-Type"),
+		Length:      resp.ContentLength,
+	}
+	if md5 := resp.Header.Get("Content-MD5"); md5 != "" {
+		att.Digest = "md5-" + md5
+	}
+	return att, nil
+}
+
+// DeleteAttachment removes an attachment from a document, returning the new document revision.
+// DELETE /{db}/{docid}/{attname}
+func (d *Database) DeleteAttachment(ctx context.Context, docID, rev, name string) (string, error) {
+	req, err := http.NewRequest("DELETE", attachmentPath(docID, name), nil)
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+	values := req.URL.Query()
+	values.Set("rev", rev)
+	req.URL.RawQuery = values.Encode()
+
+	resp, err := d.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return "", newError(req, resp, nil)
+	}
+	res := revResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return "", err
+	}
+	return res.Rev, nil
+}
@@ -0,0 +1,165 @@
+// +build !integration
+
+package couchdb
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDatabase_Attachments(t *testing.T) {
+	t.Parallel()
+
+	db := client.Database("attachment-test")
+	client.Databases.Create(db.Name, DatabaseClusterOptions{})
+	defer client.Databases.Delete(db.Name)
+
+	var rev string
+	t.Run("put", func(t *testing.T) {
+		var err error
+		rev, err = db.PutAttachment(context.Background(), "invoice:1", "", "my notes.txt", "text/plain", strings.NewReader("hello world"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rev == "" {
+			t.Fatal("Expected to receive a document revision, but got nothing")
+		}
+	})
+
+	t.Run("stub", func(t *testing.T) {
+		var doc Document
+		if err := db.Get(context.Background(), "invoice:1", &doc); err != nil {
+			t.Fatal(err)
+		}
+		att, ok := doc.Attachments["my notes.txt"]
+		if !ok || !att.Stub {
+			t.Fatal("Expected attachment stub on document")
+		}
+		if att.ContentType != "text/plain" || att.Length != 11 {
+			t.Fatalf("Unexpected attachment metadata: %+v", att)
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		att, err := db.GetAttachment(context.Background(), "invoice:1", "my notes.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer att.Close()
+		bs, err := ioutil.ReadAll(att)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(bs) != "hello world" {
+			t.Fatalf("Expected attachment body %q, got %q", "hello world", string(bs))
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		newRev, err := db.DeleteAttachment(context.Background(), "invoice:1", rev, "my notes.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if newRev == rev {
+			t.Fatalf("Expected new revision, but got %q", newRev)
+		}
+	})
+}
+
+func TestDatabase_Multipart(t *testing.T) {
+	t.Parallel()
+
+	db := client.Database("multipart-test")
+	client.Databases.Create(db.Name, DatabaseClusterOptions{})
+	defer client.Databases.Delete(db.Name)
+
+	body := "hello world"
+	// given out of alphabetical order, as couchdb matches parts to stubs by their order
+	rev, err := db.PutMultipart(context.Background(), "invoice:1", testDoc{Name: "Invoice"}, []MultipartAttachment{
+		{Name: "z.txt", ContentType: "text/plain", Length: 1, Body: strings.NewReader("z")},
+		{Name: "notes.txt", ContentType: "text/plain", Length: int64(len(body)), Body: strings.NewReader(body)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev == "" {
+		t.Fatal("Expected to receive a document revision, but got nothing")
+	}
+
+	mp, err := db.GetMultipart(context.Background(), "invoice:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mp.Close()
+
+	var doc testDoc
+	if err := mp.Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Name != "Invoice" {
+		t.Fatalf("Expected document name %q, got %q", "Invoice", doc.Name)
+	}
+	bodies := map[string]string{}
+	for i := 0; i < 2; i++ {
+		att, err := mp.NextAttachment()
+		if err != nil {
+			t.Fatal(err)
+		}
+		bs, err := ioutil.ReadAll(att)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bodies[att.Name] = string(bs)
+	}
+	if bodies["notes.txt"] != body || bodies["z.txt"] != "z" {
+		t.Fatalf("Unexpected attachments %q", bodies)
+	}
+}
+
+func TestMultipartBody(t *testing.T) {
+	t.Parallel()
+
+	body, attachments, err := multipartBody(testDoc{Name: "Invoice"}, []MultipartAttachment{
+		{Name: "z.txt", ContentType: "text/plain", Length: 1},
+		{Name: "a.txt", ContentType: "text/plain", Length: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attachments) != 2 || attachments[0].Name != "a.txt" || attachments[1].Name != "z.txt" {
+		t.Fatalf("Expected attachments to be sorted by name, got %+v", attachments)
+	}
+	if strings.Index(string(body), `"a.txt"`) > strings.Index(string(body), `"z.txt"`) {
+		t.Fatalf("Expected stubs in the order of the parts, got %s", body)
+	}
+}
+
+func TestDatabase_AttachmentNameEscaping(t *testing.T) {
+	t.Parallel()
+
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"ok":true,"id":"invoice:1","rev":"1-a"}`))
+	}))
+	defer srv.Close()
+
+	db := (&Client{Host: srv.URL, client: &http.Client{}}).Database("attachments")
+	for name, expected := range map[string]string{
+		"my file.pdf":    "/attachments/invoice:1/my%20file.pdf",
+		"a/b 100%.txt":   "/attachments/invoice:1/a%2Fb%20100%25.txt",
+		"résumé (1).pdf": "/attachments/invoice:1/r%C3%A9sum%C3%A9%20%281%29.pdf",
+	} {
+		if _, err := db.PutAttachment(context.Background(), "invoice:1", "", name, "text/plain", strings.NewReader("x")); err != nil {
+			t.Fatal(err)
+		}
+		if path != expected {
+			t.Fatalf("Expected %q to be sent as %q, got %q", name, expected, path)
+		}
+	}
+}
@@ -1,7 +1,17 @@
 package couchdb
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Authentication is used to allow couchdb to support multiple authentication methods
@@ -9,6 +19,14 @@ type Authentication interface {
 	Decorate(*http.Request) error
 }
 
+// renewableAuthentication is implemented by authentication methods whose credentials expire.
+// When couchdb rejects a request with 401 the credentials used are invalidated and the
+// request is sent once more.
+type renewableAuthentication interface {
+	Authentication
+	Invalidate(*http.Request)
+}
+
 // BasicAuthentication uses basic authorization for couchdb API requests
 type BasicAuthentication struct {
 	username string
@@ -32,3 +50,257 @@ func WithBasicAuthentication(username, password string) func(*Client) error {
 		return nil
 	}
 }
+
+// DefaultSessionTimeout is the lifetime assumed for session cookies which do not carry an expiry.
+// It matches the default of couchdb's [chttpd_auth] timeout setting.
+const DefaultSessionTimeout = 10 * time.Minute
+
+// sessionCookieName is the name of the cookie issued by POST /_session
+const sessionCookieName = "AuthSession"
+
+// CookieAuthentication uses couchdb session cookies for API requests. The cookie is
+// obtained lazily, renewed before it expires or after couchdb rejected it, and shared
+// between all goroutines using the client.
+type CookieAuthentication struct {
+	username string
+	password string
+	c        *Client
+
+	mu      sync.Mutex
+	cookie  *http.Cookie
+	renewAt time.Time
+}
+
+// Decorate adds the current session cookie to the request, logging in if necessary
+func (a *CookieAuthentication) Decorate(r *http.Request) error {
+	cookie, err := a.session(r.Context())
+	if err != nil {
+		return err
+	}
+	r.AddCookie(cookie)
+	return nil
+}
+
+// Invalidate drops the session cookie used by r, forcing the next request to log in again
+func (a *CookieAuthentication) Invalidate(r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cookie == nil {
+		return
+	}
+	if used, err := r.Cookie(sessionCookieName); err == nil && used.Value == a.cookie.Value {
+		a.cookie = nil
+	}
+}
+
+// Logout ends the current session, if any. Subsequent requests log in again.
+func (a *CookieAuthentication) Logout(ctx context.Context) error {
+	a.mu.Lock()
+	cookie := a.cookie
+	a.cookie = nil
+	a.mu.Unlock()
+	if cookie == nil {
+		return nil
+	}
+	return a.c.Sessions.Logout(ctx, cookie)
+}
+
+func (a *CookieAuthentication) session(ctx context.Context) (*http.Cookie, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	if a.cookie != nil && now.Before(a.renewAt) {
+		return a.cookie, nil
+	}
+
+	cookie, err := a.c.Sessions.Login(ctx, a.username, a.password)
+	if err != nil {
+		return nil, err
+	}
+	expires := now.Add(DefaultSessionTimeout)
+	if cookie.MaxAge > 0 {
+		expires = now.Add(time.Duration(cookie.MaxAge) * time.Second)
+	} else if !cookie.Expires.IsZero() {
+		expires = cookie.Expires
+	}
+	// renew once 90% of the lifetime has passed, mirroring couchdb's own refresh threshold
+	a.cookie = cookie
+	a.renewAt = now.Add(expires.Sub(now) * 9 / 10)
+	return cookie, nil
+}
+
+// WithCookieAuthentication returns a new session cookie authentication mechanism
+func WithCookieAuthentication(username, password string) func(*Client) error {
+	return func(c *Client) error {
+		c.Authenticator = &CookieAuthentication{
+			username: username,
+			password: password,
+			c:        c,
+		}
+		return nil
+	}
+}
+
+// TokenSource provides bearer tokens for JWT authentication
+type TokenSource interface {
+	Token(context.Context) (string, error)
+}
+
+// TokenSourceFunc adapts a function minting or refreshing tokens to the TokenSource interface
+type TokenSourceFunc func(context.Context) (string, error)
+
+// Token calls f(ctx)
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// StaticToken returns a TokenSource which always provides the given token
+func StaticToken(token string) TokenSource {
+	return TokenSourceFunc(func(context.Context) (string, error) {
+		return token, nil
+	})
+}
+
+// tokenExpiryLeeway is subtracted from a token's exp claim so it is not sent right before expiring
+const tokenExpiryLeeway = 10 * time.Second
+
+// JWTAuthentication uses bearer tokens for couchdb API requests, as supported by couchdb's
+// jwt_authentication_handler. Tokens are cached until their exp claim is reached or couchdb
+// rejects them, after which the TokenSource is asked for a new one.
+type JWTAuthentication struct {
+	source TokenSource
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// Decorate adds the current bearer token to the request
+func (a *JWTAuthentication) Decorate(r *http.Request) error {
+	token, err := a.current(r.Context())
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Invalidate drops the token used by r, forcing the next request to fetch a new one
+func (a *JWTAuthentication) Invalidate(r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.token != "" && r.Header.Get("Authorization") == "Bearer "+a.token {
+		a.token = ""
+	}
+}
+
+func (a *JWTAuthentication) current(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" && (a.expires.IsZero() || time.Now().Before(a.expires)) {
+		return a.token, nil
+	}
+	token, err := a.source.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	a.token = token
+	a.expires = time.Time{}
+	if exp, ok := tokenExpiry(token); ok {
+		a.expires = exp.Add(-tokenExpiryLeeway)
+	}
+	return token, nil
+}
+
+// tokenExpiry extracts the exp claim of a JWT without verifying it
+func tokenExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	bs, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+	claims := struct {
+		Expires int64 `json:"exp"`
+	}{}
+	if err := json.Unmarshal(bs, &claims); err != nil || claims.Expires == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Expires, 0), true
+}
+
+// WithJWTAuthentication returns a new bearer token authentication mechanism
+func WithJWTAuthentication(source TokenSource) func(*Client) error {
+	return func(c *Client) error {
+		c.Authenticator = &JWTAuthentication{
+			source: source,
+		}
+		return nil
+	}
+}
+
+// ProxyUser identifies the user a request is made on behalf of when using proxy authentication
+type ProxyUser struct {
+	Name  string
+	Roles []string
+}
+
+type proxyUserKey struct{}
+
+// WithProxyUser returns a context which makes proxy authenticated requests act on behalf of user
+func WithProxyUser(ctx context.Context, user ProxyUser) context.Context {
+	return context.WithValue(ctx, proxyUserKey{}, user)
+}
+
+// ProxyUserFromContext returns the user stored in ctx by WithProxyUser, if any
+func ProxyUserFromContext(ctx context.Context) (ProxyUser, bool) {
+	user, ok := ctx.Value(proxyUserKey{}).(ProxyUser)
+	return user, ok
+}
+
+// ProxyAuthentication uses couchdb's proxy_authentication_handler, acting on behalf of the
+// user stored in each request's context. Requests without a user are sent unauthenticated.
+type ProxyAuthentication struct {
+	secret string
+	hash   func() hash.Hash
+}
+
+// Decorate adds the proxy authentication headers for the context's user to the request
+func (p ProxyAuthentication) Decorate(r *http.Request) error {
+	user, ok := ProxyUserFromContext(r.Context())
+	if !ok {
+		return nil
+	}
+	r.Header.Set("X-Auth-CouchDB-UserName", user.Name)
+	r.Header.Set("X-Auth-CouchDB-Roles", strings.Join(user.Roles, ","))
+	if p.secret != "" {
+		mac := hmac.New(p.hash, []byte(p.secret))
+		mac.Write([]byte(user.Name))
+		r.Header.Set("X-Auth-CouchDB-Token", hex.EncodeToString(mac.Sum(nil)))
+	}
+	return nil
+}
+
+// WithProxyAuthentication returns a new proxy authentication mechanism signing tokens with
+// HMAC-SHA1, which every couchdb version accepts. An empty secret omits the token header,
+// for servers configured with proxy_use_secret = false.
+func WithProxyAuthentication(secret string) func(*Client) error {
+	return WithProxyAuthenticationHash(secret, sha1.New)
+}
+
+// WithProxyAuthenticationHash returns a new proxy authentication mechanism signing tokens
+// with the given hash, e.g. sha256.New for servers restricting [chttpd_auth] hash_algorithms
+func WithProxyAuthenticationHash(secret string, h func() hash.Hash) func(*Client) error {
+	authenticator := ProxyAuthentication{
+		secret: secret,
+		hash:   h,
+	}
+	return func(c *Client) error {
+		c.Authenticator = authenticator
+		return nil
+	}
+}
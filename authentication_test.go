@@ -0,0 +1,144 @@
+// +build !integration
+
+package couchdb
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// unsignedToken builds a JWT with the given claims, which tokenExpiry does not verify
+func unsignedToken(claims string) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	return fmt.Sprintf("%s.%s.signature", header, base64.RawURLEncoding.EncodeToString([]byte(claims)))
+}
+
+func TestTokenExpiry(t *testing.T) {
+	t.Parallel()
+
+	padded := "header." + base64.URLEncoding.EncodeToString([]byte(`{"exp": 1600000000}`)) + ".signature"
+	for _, tc := range []struct {
+		name    string
+		token   string
+		expires time.Time
+		ok      bool
+	}{
+		{"exp claim", unsignedToken(`{"sub":"michael","exp":1600000000}`), time.Unix(1600000000, 0), true},
+		{"padded claims", padded, time.Unix(1600000000, 0), true},
+		{"no exp claim", unsignedToken(`{"sub":"michael"}`), time.Time{}, false},
+		{"zero exp claim", unsignedToken(`{"exp":0}`), time.Time{}, false},
+		{"claims not json", unsignedToken(`michael`), time.Time{}, false},
+		{"claims not base64", "header.!!!.signature", time.Time{}, false},
+		{"not a jwt", "opaque-token", time.Time{}, false},
+	} {
+		expires, ok := tokenExpiry(tc.token)
+		if ok != tc.ok || !expires.Equal(tc.expires) {
+			t.Fatalf("%s: expected %v, %v, got %v, %v", tc.name, tc.expires, tc.ok, expires, ok)
+		}
+	}
+}
+
+func TestJWTAuthentication(t *testing.T) {
+	t.Parallel()
+
+	tokens := []string{
+		unsignedToken(fmt.Sprintf(`{"sub":"michael","exp":%d}`, time.Now().Add(time.Hour).Unix())),
+		unsignedToken(fmt.Sprintf(`{"sub":"michael","exp":%d}`, time.Now().Add(time.Hour).Unix())) + "2",
+		// expires within the leeway, so it is never reused
+		unsignedToken(fmt.Sprintf(`{"sub":"michael","exp":%d}`, time.Now().Add(time.Second).Unix())),
+	}
+	fetched := 0
+	auth := &JWTAuthentication{source: TokenSourceFunc(func(context.Context) (string, error) {
+		token := tokens[fetched]
+		fetched++
+		return token, nil
+	})}
+	decorate := func() *http.Request {
+		req, _ := http.NewRequest("GET", "/", nil)
+		if err := auth.Decorate(req); err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+
+	first := decorate()
+	if first.Header.Get("Authorization") != "Bearer "+tokens[0] || decorate().Header.Get("Authorization") != "Bearer "+tokens[0] {
+		t.Fatal("Expected token to be cached until it expires")
+	}
+
+	// a request rejected with 401 after another one renewed the token must not drop the new token
+	stale, _ := http.NewRequest("GET", "/", nil)
+	stale.Header.Set("Authorization", "Bearer outdated")
+	auth.Invalidate(stale)
+	if decorate().Header.Get("Authorization") != "Bearer "+tokens[0] || fetched != 1 {
+		t.Fatal("Expected token not to be dropped by requests using another token")
+	}
+
+	auth.Invalidate(first)
+	second := decorate()
+	if second.Header.Get("Authorization") != "Bearer "+tokens[1] || fetched != 2 {
+		t.Fatal("Expected a new token to be fetched after couchdb rejected the current one")
+	}
+	auth.Invalidate(first)
+	if decorate().Header.Get("Authorization") != "Bearer "+tokens[1] || fetched != 2 {
+		t.Fatal("Expected the new token to be kept when the rejected one is invalidated again")
+	}
+
+	auth.Invalidate(second)
+	decorate()
+	tokens = append(tokens, "opaque-token")
+	if decorate().Header.Get("Authorization") != "Bearer opaque-token" || fetched != 4 {
+		t.Fatal("Expected token to be fetched again once it expires")
+	}
+}
+
+func TestProxyAuthentication_Decorate(t *testing.T) {
+	t.Parallel()
+
+	user := ProxyUser{Name: "michael", Roles: []string{"sales", "_admin"}}
+	for _, tc := range []struct {
+		name  string
+		opt   func(*Client) error
+		token string
+	}{
+		{"sha1", WithProxyAuthentication("92de07df7e7a3fe14808cef90a7cc0d91"), "977993dc75e8152c2d0ed87c62f464bba0ca8796"},
+		{"sha256", WithProxyAuthenticationHash("92de07df7e7a3fe14808cef90a7cc0d91", sha256.New), "8b96a478a544a91c106c28ce3df49dc1975a2ff0cc72144b96dd72f6ae7369ac"},
+		{"no secret", WithProxyAuthentication(""), ""},
+	} {
+		c := &Client{}
+		if err := tc.opt(c); err != nil {
+			t.Fatal(err)
+		}
+		req, _ := http.NewRequest("GET", "/", nil)
+		req = req.WithContext(WithProxyUser(context.Background(), user))
+		if err := c.Authenticator.Decorate(req); err != nil {
+			t.Fatal(err)
+		}
+		if name := req.Header.Get("X-Auth-CouchDB-UserName"); name != "michael" {
+			t.Fatalf("%s: expected user name %q, got %q", tc.name, "michael", name)
+		}
+		if roles := req.Header.Get("X-Auth-CouchDB-Roles"); roles != "sales,_admin" {
+			t.Fatalf("%s: expected roles %q, got %q", tc.name, "sales,_admin", roles)
+		}
+		token, ok := req.Header["X-Auth-Couchdb-Token"]
+		if tc.token == "" && ok {
+			t.Fatalf("%s: expected no token, got %q", tc.name, token)
+		}
+		if tc.token != "" && req.Header.Get("X-Auth-CouchDB-Token") != tc.token {
+			t.Fatalf("%s: expected token %q, got %q", tc.name, tc.token, token)
+		}
+
+		anonymous, _ := http.NewRequest("GET", "/", nil)
+		if err := c.Authenticator.Decorate(anonymous); err != nil {
+			t.Fatal(err)
+		}
+		if len(anonymous.Header) != 0 {
+			t.Fatalf("%s: expected request without user to be sent unauthenticated, got %v", tc.name, anonymous.Header)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package couchdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// BulkDocsOpts defines parameters for writing many documents with a single request
+type BulkDocsOpts struct {
+	// NewEdits set to false stores the given revisions as-is instead of creating new ones,
+	// as done by replication. couchdb only reports failed documents in this mode.
+	NewEdits *bool
+}
+
+// BulkResult is the outcome of writing a single document using BulkDocs
+type BulkResult struct {
+	ID     string `json:"id"`
+	Rev    string `json:"rev,omitempty"`
+	OK     bool   `json:"ok,omitempty"`
+	Type   string `json:"error,omitempty"`
+	Reason string `json:"reason,omitempty"`
+
+	path string
+}
+
+// bulkErrorStatus maps per-document error types to the status couchdb uses for single document requests
+var bulkErrorStatus = map[string]int{
+	"bad_request":        http.StatusBadRequest,
+	"unauthorized":       http.StatusUnauthorized,
+	"forbidden":          http.StatusForbidden,
+	"not_found":          http.StatusNotFound,
+	"conflict":           http.StatusConflict,
+	"document_too_large": http.StatusRequestEntityTooLarge,
+	"too_large":          http.StatusRequestEntityTooLarge,
+}
+
+// Err returns an *Error if the document could not be written, and nil otherwise
+func (r BulkResult) Err() error {
+	if r.Type == "" {
+		return nil
+	}
+	return &Error{
+		ErrorResponse: ErrorResponse{
+			Type:   r.Type,
+			Reason: r.Reason,
+		},
+		StatusCode: bulkErrorStatus[r.Type],
+		Method:     "POST",
+		Path:       r.path,
+	}
+}
+
+type bulkDocsRequest struct {
+	Docs     interface{} `json:"docs"`
+	NewEdits *bool       `json:"new_edits,omitempty"`
+}
+
+// BulkDocs creates, updates or deletes many documents with a single request. POST /{db}/_bulk_docs
+// docs must be a slice; the returned results are in the same order. Failing documents do not
+// fail the whole request, so check each result's Err.
+//
+//   results, err := db.BulkDocs(ctx, []couchdb.Document{{ID: "a"}, {ID: "b"}}, couchdb.BulkDocsOpts{})
+//   for _, result := range results {
+//     if errors.Is(result.Err(), couchdb.ErrConflict) {
+//       // …
+//     }
+//   }
+func (d *Database) BulkDocs(ctx context.Context, docs interface{}, opts BulkDocsOpts) ([]BulkResult, error) {
+	if reflect.ValueOf(docs).Kind() != reflect.Slice {
+		return nil, fmt.Errorf("couchdb: BulkDocs expects a slice of documents, got %T", docs)
+	}
+	bs, err := json.Marshal(bulkDocsRequest{
+		Docs:     docs,
+		NewEdits: opts.NewEdits,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", "/_bulk_docs", bytes.NewReader(bs))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	resp, err := d.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusAccepted {
+		return nil, newError(req, resp, nil)
+	}
+
+	results := []BulkResult{}
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].path = req.URL.Path
+	}
+	return results, nil
+}
+
+// BulkDocsChunked writes docs using as many BulkDocs requests of at most size documents as needed.
+// If a request fails, the results of all previous requests are returned alongside the error.
+func (d *Database) BulkDocsChunked(ctx context.Context, docs interface{}, size int, opts BulkDocsOpts) ([]BulkResult, error) {
+	if size < 1 {
+		return nil, errors.New("couchdb: BulkDocsChunked requires a positive chunk size")
+	}
+	v := reflect.ValueOf(docs)
+	if v.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("couchdb: BulkDocsChunked expects a slice of documents, got %T", docs)
+	}
+
+	results := []BulkResult{}
+	for start := 0; start < v.Len(); start += size {
+		end := start + size
+		if end > v.Len() {
+			end = v.Len()
+		}
+		chunk, err := d.BulkDocs(ctx, v.Slice(start, end).Interface(), opts)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, chunk...)
+	}
+	return results, nil
+}
+
+// BulkGetRequest identifies a document to fetch using BulkGet. If Rev is empty,
+// the current revision is returned.
+type BulkGetRequest struct {
+	ID  string `json:"id"`
+	Rev string `json:"rev,omitempty"`
+}
+
+// BulkGetOpts defines parameters for fetching many documents with a single request
+type BulkGetOpts struct {
+	// Revs includes the revision history of every document
+	Revs bool
+	// Attachments includes attachment bodies instead of stubs
+	Attachments bool
+}
+
+// BulkGetError describes a document which could not be fetched using BulkGet
+type BulkGetError struct {
+	ID     string `json:"id"`
+	Rev    string `json:"rev"`
+	Type   string `json:"error"`
+	Reason string `json:"reason"`
+}
+
+// Err converts the error into an *Error, e.g. to check for ErrNotFound
+func (e BulkGetError) Err() error {
+	return &Error{
+		ErrorResponse: ErrorResponse{
+			Type:   e.Type,
+			Reason: e.Reason,
+		},
+		StatusCode: bulkErrorStatus[e.Type],
+		Method:     "POST",
+		Path:       "/_bulk_get",
+	}
+}
+
+type bulkGetRequest struct {
+	Docs []BulkGetRequest `json:"docs"`
+}
+
+// BulkGet fetches many documents, or specific revisions of them, with a single request.
+// POST /{db}/_bulk_get
+// Documents which can not be fetched are reported per document instead of failing the request,
+// so results should be shaped like this:
+//
+//   type results struct {
+//     Results []struct {
+//       ID   string `json:"id"`
+//       Docs []struct {
+//         OK    *couchdb.Document     `json:"ok"`
+//         Error *couchdb.BulkGetError `json:"error"`
+//       } `json:"docs"`
+//     } `json:"results"`
+//   }
+func (d *Database) BulkGet(ctx context.Context, docs []BulkGetRequest, opts BulkGetOpts, results interface{}) error {
+	bs, err := json.Marshal(bulkGetRequest{docs})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", "/_bulk_get", bytes.NewReader(bs))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	values := url.Values{}
+	if opts.Revs {
+		values.Set("revs", strconv.FormatBool(opts.Revs))
+	}
+	if opts.Attachments {
+		values.Set("attachments", strconv.FormatBool(opts.Attachments))
+	}
+	req.URL.RawQuery = values.Encode()
+	// without this header couchdb answers with multipart/mixed
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := d.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return newError(req, resp, nil)
+	}
+	return json.NewDecoder(resp.Body).Decode(results)
+}
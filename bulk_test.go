@@ -0,0 +1,95 @@
+// +build !integration
+
+package couchdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestDatabase_BulkDocs(t *testing.T) {
+	t.Parallel()
+
+	db := client.Database("bulk-test")
+	client.Databases.Create(db.Name, DatabaseClusterOptions{})
+	defer client.Databases.Delete(db.Name)
+
+	t.Run("insert", func(t *testing.T) {
+		docs := []testDoc{
+			{Document: Document{ID: "employee:michael"}, Name: "Michael"},
+			{Document: Document{ID: "employee:raphael"}, Name: "Raphael"},
+		}
+		results, err := db.BulkDocs(context.Background(), docs, BulkDocsOpts{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != len(docs) {
+			t.Fatalf("Expected %d results, got %d", len(docs), len(results))
+		}
+		for _, result := range results {
+			if result.Err() != nil || result.Rev == "" {
+				t.Fatalf("Expected %q to be written, but wasn't: %v", result.ID, result.Err())
+			}
+		}
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		results, err := db.BulkDocs(context.Background(), []testDoc{
+			{Document: Document{ID: "employee:michael"}, Name: "Michael"},
+		}, BulkDocsOpts{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !errors.Is(results[0].Err(), ErrConflict) {
+			t.Fatalf("Expected conflict, got %v", results[0].Err())
+		}
+	})
+
+	t.Run("chunked", func(t *testing.T) {
+		docs := []testDoc{}
+		for i := 0; i < 25; i++ {
+			docs = append(docs, testDoc{Document: Document{ID: fmt.Sprintf("pet:%d", i)}})
+		}
+		results, err := db.BulkDocsChunked(context.Background(), docs, 10, BulkDocsOpts{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != len(docs) {
+			t.Fatalf("Expected %d results, got %d", len(docs), len(results))
+		}
+	})
+}
+
+func TestDatabase_BulkGet(t *testing.T) {
+	t.Parallel()
+	if !client.CouchDB.HasClusterSupport() {
+		t.Skip("_bulk_get requires couchdb 2.x")
+	}
+
+	var results struct {
+		Results []struct {
+			ID   string `json:"id"`
+			Docs []struct {
+				OK    *testDoc      `json:"ok"`
+				Error *BulkGetError `json:"error"`
+			} `json:"docs"`
+		} `json:"results"`
+	}
+	if err := playground.BulkGet(context.Background(), []BulkGetRequest{
+		{ID: "employee:michael"},
+		{ID: "employee:unknown"},
+	}, BulkGetOpts{Revs: true}, &results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results.Results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results.Results))
+	}
+	if doc := results.Results[0].Docs[0].OK; doc == nil || doc.Name != "Michael" {
+		t.Fatal("Expected doc to be fetched")
+	}
+	if err := results.Results[1].Docs[0].Error; err == nil || !errors.Is(err.Err(), ErrNotFound) {
+		t.Fatal("Expected missing doc to be reported")
+	}
+}
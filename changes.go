@@ -0,0 +1,246 @@
+package couchdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// StyleAllDocs makes changes list all leaf revisions instead of only the winning one
+const StyleAllDocs = "all_docs"
+
+// Built-in filters for changes feeds and replications
+const (
+	// FilterDocIDs passes changes of a given list of documents
+	FilterDocIDs = "_doc_ids"
+	// FilterSelector passes changes of documents matching a Mango selector
+	FilterSelector = "_selector"
+	// FilterView passes changes of documents emitted by a view's map function
+	FilterView = "_view"
+	// FilterDesign passes changes of design documents
+	FilterDesign = "_design"
+)
+
+// ChangesOpts defines parameters for reading the changes feed of a database
+type ChangesOpts struct {
+	// Feed is one of FeedNormal, FeedLongpoll, FeedContinuous or FeedEventSource
+	Feed string
+	// Since starts the feed after the given sequence, or at SinceNow
+	Since       Sequence
+	IncludeDocs bool
+	// Conflicts adds the conflicting revisions to included documents
+	Conflicts bool
+	// Heartbeat makes couchdb send empty lines while no changes occur, keeping the connection alive
+	Heartbeat time.Duration
+	// Timeout ends a response if no changes occurred in time
+	Timeout time.Duration
+	// Limit caps the number of changes per response. Continuous and eventsource feeds stop once
+	// a response ends if a limit is set.
+	Limit      int
+	Descending bool
+	// Style set to StyleAllDocs lists all leaf revisions of changed documents
+	Style string
+
+	// Filter is either a built-in filter or a filter function of a design document, named
+	// ddoc/filter. It is inferred if DocIDs, Selector or View are set.
+	Filter string
+	// DocIDs restricts the feed to the given documents
+	DocIDs []string
+	// Selector restricts the feed to documents matching the Mango selector
+	Selector interface{}
+	// View restricts the feed to documents emitted by the map function of the view named ddoc/view
+	View string
+	// QueryParams are passed to filter functions as req.query
+	QueryParams map[string]string
+}
+
+type changesFilter struct {
+	DocIDs   []string    `json:"doc_ids,omitempty"`
+	Selector interface{} `json:"selector,omitempty"`
+}
+
+// filter returns the filter to apply, inferring built-in ones from the options set
+func (opts ChangesOpts) filter() string {
+	switch {
+	case opts.Filter != "":
+		return opts.Filter
+	case opts.DocIDs != nil:
+		return FilterDocIDs
+	case opts.Selector != nil:
+		return FilterSelector
+	case opts.View != "":
+		return FilterView
+	}
+	return ""
+}
+
+// ChangeRev identifies a revision of a changed document
+type ChangeRev struct {
+	Rev string `json:"rev"`
+}
+
+// Change describes a single document change
+type Change struct {
+	Seq     Sequence        `json:"seq"`
+	ID      string          `json:"id"`
+	Changes []ChangeRev     `json:"changes"`
+	Deleted bool            `json:"deleted"`
+	Doc     json.RawMessage `json:"doc,omitempty"`
+}
+
+// ScanDoc decodes the document included with IncludeDocs into doc
+func (c Change) ScanDoc(doc interface{}) error {
+	if len(c.Doc) == 0 {
+		return errors.New("couchdb: change does not include a document")
+	}
+	return json.Unmarshal(c.Doc, doc)
+}
+
+// ChangesFeed iterates over the changes of a database. Normal and longpoll feeds end after a
+// single response; continuous and eventsource feeds run until the context is cancelled or the
+// feed is closed. After network errors the feed reconnects, resuming after the last change seen.
+//
+//   feed, err := db.Changes(ctx, couchdb.ChangesOpts{Feed: couchdb.FeedContinuous})
+//   if err != nil {
+//     return err
+//   }
+//   defer feed.Close()
+//   for feed.Next() {
+//     change := feed.Change()
+//     // …
+//   }
+//   return feed.Err()
+type ChangesFeed struct {
+	feed   *feed
+	change Change
+	err    error
+}
+
+// Changes opens the changes feed of the database. GET /{db}/_changes
+// Feeds filtered by DocIDs or Selector use POST /{db}/_changes instead.
+func (d *Database) Changes(ctx context.Context, opts ChangesOpts) (*ChangesFeed, error) {
+	follow := (opts.Feed == FeedContinuous || opts.Feed == FeedEventSource) && opts.Limit == 0
+	f, err := newFeed(ctx, opts.Feed, follow, opts.Since, func(ctx context.Context, since Sequence) (*http.Response, error) {
+		return d.changes(ctx, opts, since)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ChangesFeed{feed: f}, nil
+}
+
+func (d *Database) changes(ctx context.Context, opts ChangesOpts, since Sequence) (*http.Response, error) {
+	method, body := "GET", []byte{}
+	filter := opts.filter()
+	// document ids and selectors are sent as body, as they may not fit into the URL
+	if filter == FilterDocIDs || filter == FilterSelector {
+		bs, err := json.Marshal(changesFilter{
+			DocIDs:   opts.DocIDs,
+			Selector: opts.Selector,
+		})
+		if err != nil {
+			return nil, err
+		}
+		method, body = "POST", bs
+	}
+	req, err := http.NewRequest(method, "/_changes", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	values := req.URL.Query()
+	for key, value := range opts.QueryParams {
+		values.Set(key, value)
+	}
+	if filter != "" {
+		values.Set("filter", filter)
+	}
+	if opts.View != "" {
+		values.Set("view", opts.View)
+	}
+	if opts.Feed != "" {
+		values.Set("feed", opts.Feed)
+	}
+	if since != "" {
+		values.Set("since", string(since))
+	}
+	if opts.IncludeDocs {
+		values.Set("include_docs", "true")
+	}
+	if opts.Conflicts {
+		values.Set("conflicts", "true")
+	}
+	if opts.Heartbeat > 0 {
+		values.Set("heartbeat", strconv.FormatInt(int64(opts.Heartbeat/time.Millisecond), 10))
+	}
+	if opts.Timeout > 0 {
+		values.Set("timeout", strconv.FormatInt(int64(opts.Timeout/time.Millisecond), 10))
+	}
+	if opts.Limit > 0 {
+		values.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Descending {
+		values.Set("descending", "true")
+	}
+	if opts.Style != "" {
+		values.Set("style", opts.Style)
+	}
+	req.URL.RawQuery = values.Encode()
+
+	resp, err := d.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, newError(req, resp, nil)
+	}
+	return resp, nil
+}
+
+// Next advances to the next change, returning false once the feed ended or failed
+func (f *ChangesFeed) Next() bool {
+	if f.err != nil || !f.feed.next() {
+		return false
+	}
+	change := Change{}
+	if err := json.Unmarshal(f.feed.event, &change); err != nil {
+		f.err = err
+		return false
+	}
+	f.change = change
+	return true
+}
+
+// Change returns the current change
+func (f *ChangesFeed) Change() Change {
+	return f.change
+}
+
+// LastSeq returns the sequence of the last change seen, or the last sequence reported by couchdb
+func (f *ChangesFeed) LastSeq() Sequence {
+	return f.feed.lastSeq
+}
+
+// Pending returns the number of changes left after the last response, if reported by couchdb
+func (f *ChangesFeed) Pending() int {
+	return f.feed.pending
+}
+
+// Err returns the error which ended the feed, if any
+func (f *ChangesFeed) Err() error {
+	if f.err != nil {
+		return f.err
+	}
+	return f.feed.err
+}
+
+// Close stops the feed. It is safe to call Close while another goroutine is waiting in Next.
+func (f *ChangesFeed) Close() error {
+	return f.feed.close()
+}
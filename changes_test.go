@@ -0,0 +1,89 @@
+// +build !integration
+
+package couchdb
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDatabase_Changes(t *testing.T) {
+	t.Parallel()
+
+	db := client.Database("changes-test")
+	client.Databases.Create(db.Name, DatabaseClusterOptions{})
+	defer client.Databases.Delete(db.Name)
+
+	db.Put(context.Background(), "employee:michael", testDoc{Name: "Michael"})
+
+	t.Run("normal", func(t *testing.T) {
+		feed, err := db.Changes(context.Background(), ChangesOpts{IncludeDocs: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer feed.Close()
+
+		ids := []string{}
+		for feed.Next() {
+			var doc testDoc
+			if err := feed.Change().ScanDoc(&doc); err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, doc.ID)
+		}
+		if err := feed.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if len(ids) != 1 || ids[0] != "employee:michael" {
+			t.Fatalf("Expected change of %q, got %v", "employee:michael", ids)
+		}
+		if feed.LastSeq() == "" {
+			t.Fatal("Expected last sequence to be reported")
+		}
+	})
+
+	t.Run("doc_ids", func(t *testing.T) {
+		db.Put(context.Background(), "pet:yumi", testDoc{Name: "Yumi"})
+
+		feed, err := db.Changes(context.Background(), ChangesOpts{DocIDs: []string{"pet:yumi"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer feed.Close()
+
+		ids := []string{}
+		for feed.Next() {
+			ids = append(ids, feed.Change().ID)
+		}
+		if err := feed.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if len(ids) != 1 || ids[0] != "pet:yumi" {
+			t.Fatalf("Expected change of %q, got %v", "pet:yumi", ids)
+		}
+	})
+
+	t.Run("continuous", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		feed, err := db.Changes(ctx, ChangesOpts{
+			Feed:      FeedContinuous,
+			Since:     SinceNow,
+			Heartbeat: time.Second,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer feed.Close()
+
+		db.Put(context.Background(), "employee:raphael", testDoc{Name: "Raphael"})
+		if !feed.Next() {
+			t.Fatalf("Expected a change, got %v", feed.Err())
+		}
+		if feed.Change().ID != "employee:raphael" {
+			t.Fatalf("Expected change of %q, got %q", "employee:raphael", feed.Change().ID)
+		}
+	})
+}
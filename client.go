@@ -3,11 +3,13 @@ package couchdb
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
 // HTTPExecutor wraps http client interactions. This allows users to pass in HTTP clients with tracing support.
@@ -28,6 +30,12 @@ type Client struct {
 	Sessions      *SessionService
 	Cluster       *ClusterService
 	Authenticator Authentication
+
+	retryPolicy    *RetryPolicy
+	nodes          *nodePool
+	discoverNodes  bool
+	healthInterval time.Duration
+	stopHealth     chan struct{}
 }
 
 // NodeInfo contains the couchDB connection info
@@ -94,19 +102,19 @@ func (c *Client) Database(name string) *Database {
 
 // Do executes a http request against the specific couchdb, setting all required headers
 func (c *Client) Do(req *http.Request) (*http.Response, error) {
-	uri := fmt.Sprintf("%s%s", c.Host, req.URL)
-	u, _ := url.Parse(uri)
-	req.URL = u
-
-	req.Header.Set("Content-Type", "application/json")
+	return c.do(req, c.Authenticator)
+}
 
-	if c.Authenticator != nil {
-		if err := c.Authenticator.Decorate(req); err != nil {
-			return nil, err
-		}
+func (c *Client) do(req *http.Request, auth Authentication) (*http.Response, error) {
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
 	}
 
-	resp, err := c.client.Do(req)
+	resp, err := c.send(req, auth)
+	var decorateErr *decorateError
+	if errors.As(err, &decorateErr) {
+		return nil, decorateErr.err
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -117,12 +125,87 @@ func (c *Client) Do(req *http.Request) (*http.Response, error) {
 		if err != nil {
 			return resp, err
 		}
-		apiErr := ErrorResponse{}
-		if err := json.Unmarshal(bs, &apiErr); err == nil {
-			resp.Body = ioutil.NopCloser(bytes.NewBuffer(bs))
-			return resp, apiErr
-		}
+		resp.Body = ioutil.NopCloser(bytes.NewBuffer(bs))
+		return resp, newError(req, resp, bs)
+	}
+
+	return resp, nil
+}
+
+// decorateError marks errors preparing a request before it is sent, e.g. failing to obtain
+// credentials. They are not caused by the node, so the request neither fails over nor is retried.
+type decorateError struct {
+	err error
+}
+
+func (e *decorateError) Error() string {
+	return e.err.Error()
+}
+
+func (e *decorateError) Unwrap() error {
+	return e.err
+}
+
+// roundTrip sends a single request to host, renewing expired credentials once if couchdb rejects them
+func (c *Client) roundTrip(req *http.Request, auth Authentication, host string) (*http.Response, error) {
+	attempt, err := decorate(req, auth, host)
+	if err != nil {
+		return nil, &decorateError{err}
+	}
+	resp, err := c.client.Do(attempt)
+	if err != nil {
+		return nil, err
 	}
 
-	return resp, err
+	renewable, ok := auth.(renewableAuthentication)
+	if !ok || resp.StatusCode != http.StatusUnauthorized || !rewind(req) {
+		return resp, nil
+	}
+	resp.Body.Close()
+	renewable.Invalidate(attempt)
+
+	attempt, err = decorate(req, auth, host)
+	if err != nil {
+		return nil, &decorateError{err}
+	}
+	return c.client.Do(attempt)
+}
+
+// decorate returns a copy of req addressed to host and carrying the credentials of auth,
+// leaving req itself untouched
+func decorate(req *http.Request, auth Authentication, host string) (*http.Request, error) {
+	u, err := url.Parse(fmt.Sprintf("%s%s", host, req.URL))
+	if err != nil {
+		return nil, err
+	}
+	attempt := req.Clone(req.Context())
+	attempt.URL = u
+	if auth == nil {
+		return attempt, nil
+	}
+	if err := auth.Decorate(attempt); err != nil {
+		return nil, err
+	}
+	return attempt, nil
+}
+
+// replayable reports whether the request body can be sent more than once
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewind prepares the request body to be sent again, reporting false if it cannot be replayed
+func rewind(req *http.Request) bool {
+	if req.Body == nil || req.Body == http.NoBody {
+		return true
+	}
+	if req.GetBody == nil {
+		return false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return false
+	}
+	req.Body = body
+	return true
 }
@@ -0,0 +1,258 @@
+package couchdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// OpenRev is a single revision returned by OpenRevs. Either OK contains the revision, or
+// Missing names a revision which does not exist.
+type OpenRev struct {
+	OK      json.RawMessage `json:"ok"`
+	Missing string          `json:"missing"`
+}
+
+// OpenRevs fetches the given revisions of a document, or all leaf revisions if revs is nil.
+// With latest set, the latest leaf revision of every given revision's branch is returned.
+// GET /{db}/{id}?open_revs=…
+func (d *Database) OpenRevs(ctx context.Context, id string, revs []string, latest bool) ([]OpenRev, error) {
+	openRevs := "all"
+	if revs != nil {
+		bs, err := json.Marshal(revs)
+		if err != nil {
+			return nil, err
+		}
+		openRevs = string(bs)
+	}
+	req, err := http.NewRequest("GET", fmt.Sprintf("/%s", id), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	values := req.URL.Query()
+	values.Set("open_revs", openRevs)
+	if latest {
+		values.Set("latest", "true")
+	}
+	req.URL.RawQuery = values.Encode()
+	// couchdb responds with multipart documents unless JSON is requested explicitly
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := d.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newError(req, resp, nil)
+	}
+	results := []OpenRev{}
+	return results, json.NewDecoder(resp.Body).Decode(&results)
+}
+
+// MergeFunc merges conflicting revisions of a document into the winning document. Every
+// revision is a JSON document including _id and _rev; the winner's _id and _rev are set by
+// the resolver.
+type MergeFunc func(id string, revs []json.RawMessage) (interface{}, error)
+
+// ResolveConflicts loads all leaf revisions of a document and, if they conflict, writes the
+// result of merge as the new winning revision, then deletes all other leaves with a single
+// _bulk_docs request. It returns the winning revision, which is the current one if there
+// were no conflicts.
+//
+// The two writes are not atomic, and neither are the deletions, as _bulk_docs applies every
+// document on its own. Writing the winner first ensures no revision is deleted unless its data
+// was merged: if the winner was updated concurrently, ErrConflict is returned and nothing is
+// deleted. If deleting a loser fails, e.g. because it was updated concurrently, the winner is
+// kept and the error returned alongside its revision; the loser stays a conflict which the next
+// call merges again.
+//
+//   rev, err := db.ResolveConflicts(ctx, "employee:michael", func(id string, revs []json.RawMessage) (interface{}, error) {
+//     merged := Employee{}
+//     for _, rev := range revs {
+//       // … combine the revisions
+//     }
+//     return merged, nil
+//   })
+func (d *Database) ResolveConflicts(ctx context.Context, id string, merge MergeFunc) (string, error) {
+	openRevs, err := d.OpenRevs(ctx, id, nil, false)
+	if err != nil {
+		return "", err
+	}
+	leaves := []json.RawMessage{}
+	revs := []string{}
+	for _, openRev := range openRevs {
+		if len(openRev.OK) == 0 {
+			continue
+		}
+		leaf := Document{}
+		if err := json.Unmarshal(openRev.OK, &leaf); err != nil {
+			return "", err
+		}
+		// deleted leaves do not take part in conflicts
+		if leaf.Deleted != nil && *leaf.Deleted {
+			continue
+		}
+		leaves = append(leaves, openRev.OK)
+		revs = append(revs, leaf.Rev)
+	}
+	if len(leaves) == 0 {
+		return "", &Error{
+			ErrorResponse: ErrorResponse{Type: "not_found", Reason: "deleted"},
+			StatusCode:    http.StatusNotFound,
+			Method:        "GET",
+			Path:          fmt.Sprintf("/%s/%s", d.Name, id),
+		}
+	}
+	winner := winningRev(revs)
+	if len(leaves) == 1 {
+		return winner, nil
+	}
+
+	merged, err := merge(id, leaves)
+	if err != nil {
+		return "", err
+	}
+	doc, err := asMap(merged)
+	if err != nil {
+		return "", err
+	}
+	doc["_id"], doc["_rev"] = id, winner
+	rev, err := d.Put(ctx, id, doc)
+	if err != nil {
+		return "", err
+	}
+
+	deleted := true
+	losers := []Document{}
+	for _, loser := range revs {
+		if loser != winner {
+			losers = append(losers, Document{ID: id, Rev: loser, Deleted: &deleted})
+		}
+	}
+	results, err := d.BulkDocs(ctx, losers, BulkDocsOpts{})
+	if err != nil {
+		return rev, err
+	}
+	for _, result := range results {
+		if err := result.Err(); err != nil {
+			return rev, err
+		}
+	}
+	return rev, nil
+}
+
+// asMap converts a document into its JSON object representation
+func asMap(doc interface{}) (map[string]interface{}, error) {
+	bs, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(bs, &m); err != nil {
+		return nil, fmt.Errorf("couchdb: merged document is not a JSON object: %v", err)
+	}
+	return m, nil
+}
+
+// winningRev picks the revision couchdb considers the winner: the longest history first, then
+// the highest revision hash
+func winningRev(revs []string) string {
+	winner := ""
+	for _, rev := range revs {
+		if winner == "" || compareRevs(rev, winner) > 0 {
+			winner = rev
+		}
+	}
+	return winner
+}
+
+func compareRevs(a, b string) int {
+	posA, hashA := splitRev(a)
+	posB, hashB := splitRev(b)
+	if posA != posB {
+		if posA < posB {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(hashA, hashB)
+}
+
+func splitRev(rev string) (int, string) {
+	parts := strings.SplitN(rev, "-", 2)
+	pos, _ := strconv.Atoi(parts[0])
+	if len(parts) < 2 {
+		return pos, ""
+	}
+	return pos, parts[1]
+}
+
+// ConflictSweeper resolves conflicts of all documents of a database, following its changes
+// feed using a Consumer. Documents which become conflicted while the sweeper runs, e.g. by
+// replication, are resolved as their changes arrive.
+//
+//   sweeper := couchdb.ConflictSweeper{DB: db, ID: "conflicts", Merge: mergeEmployees}
+//   err := sweeper.Run(ctx)
+type ConflictSweeper struct {
+	DB *Database
+	// ID identifies the checkpoint of the sweeper
+	ID    string
+	Merge MergeFunc
+	// Store persists checkpoints, defaulting to a LocalCheckpointStore on DB
+	Store CheckpointStore
+	// Concurrency is the number of documents resolved in parallel, defaulting to 1
+	Concurrency int
+	// OnResolved is called after the conflicts of a document were resolved, if set
+	OnResolved func(id, rev string)
+}
+
+// Run resolves conflicts until the context is cancelled or resolving fails
+func (s *ConflictSweeper) Run(ctx context.Context) error {
+	if s.Merge == nil {
+		return errors.New("couchdb: conflict sweeper requires a Merge function")
+	}
+	consumer := Consumer{
+		DB:          s.DB,
+		ID:          s.ID,
+		Store:       s.Store,
+		Concurrency: s.Concurrency,
+		Opts: ChangesOpts{
+			IncludeDocs: true,
+			Conflicts:   true,
+		},
+		Handler: s.handle,
+	}
+	return consumer.Run(ctx)
+}
+
+func (s *ConflictSweeper) handle(ctx context.Context, change Change) error {
+	if change.Deleted || len(change.Doc) == 0 {
+		return nil
+	}
+	doc := Document{}
+	if err := change.ScanDoc(&doc); err != nil {
+		return err
+	}
+	if len(doc.Conflicts) == 0 {
+		return nil
+	}
+	rev, err := s.DB.ResolveConflicts(ctx, change.ID, s.Merge)
+	// the document changed meanwhile, so its next change is handled again. No revision was
+	// deleted without its data being merged into the winner, see ResolveConflicts.
+	if errors.Is(err, ErrConflict) || errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if s.OnResolved != nil {
+		s.OnResolved(change.ID, rev)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+// +build !integration
+
+package couchdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWinningRev(t *testing.T) {
+	t.Parallel()
+
+	winner := winningRev([]string{"2-abc", "10-aaa", "10-bbb", "9-fff"})
+	if winner != "10-bbb" {
+		t.Fatalf("Expected %q, got %q", "10-bbb", winner)
+	}
+}
+
+func TestDatabase_ResolveConflicts(t *testing.T) {
+	t.Parallel()
+
+	db := client.Database("conflicts-test")
+	client.Databases.Create(db.Name, DatabaseClusterOptions{})
+	defer client.Databases.Delete(db.Name)
+
+	newEdits := false
+	if _, err := db.BulkDocs(context.Background(), []testDoc{
+		{Document: Document{ID: "employee:conflicted", Rev: "1-aaa"}, Name: "Alice"},
+		{Document: Document{ID: "employee:conflicted", Rev: "1-bbb"}, Name: "Bob"},
+	}, BulkDocsOpts{NewEdits: &newEdits}); err != nil {
+		t.Fatal(err)
+	}
+
+	doc := testDoc{}
+	if err := db.GetWithOpts(context.Background(), "employee:conflicted", GetOpts{Conflicts: true}, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Conflicts) != 1 {
+		t.Fatalf("Expected 1 conflict, got %v", doc.Conflicts)
+	}
+
+	rev, err := db.ResolveConflicts(context.Background(), "employee:conflicted", func(id string, revs []json.RawMessage) (interface{}, error) {
+		merged := testDoc{}
+		for _, rev := range revs {
+			doc := testDoc{}
+			if err := json.Unmarshal(rev, &doc); err != nil {
+				return nil, err
+			}
+			if merged.Name != "" {
+				merged.Name += " & "
+			}
+			merged.Name += doc.Name
+		}
+		return merged, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc = testDoc{}
+	if err := db.GetWithOpts(context.Background(), "employee:conflicted", GetOpts{Conflicts: true}, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Rev != rev || len(doc.Conflicts) != 0 || len(doc.Name) != len("Alice & Bob") {
+		t.Fatalf("Expected merged document without conflicts, got %#v", doc)
+	}
+}
+
+func TestDatabase_ResolveConflicts_Order(t *testing.T) {
+	t.Parallel()
+
+	for name, putStatus := range map[string]int{"merged": http.StatusCreated, "conflict": http.StatusConflict} {
+		putStatus := putStatus
+		t.Run(name, func(t *testing.T) {
+			requests := []string{}
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				bs, _ := ioutil.ReadAll(r.Body)
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				switch r.Method {
+				case "GET":
+					w.Write([]byte(`[{"ok":{"_id":"x","_rev":"2-a","n":1}},{"ok":{"_id":"x","_rev":"2-b","n":2}}]`))
+				case "PUT":
+					if string(bs) != `{"_id":"x","_rev":"2-b","n":3}` {
+						t.Errorf("Unexpected winner %s", bs)
+					}
+					if putStatus == http.StatusConflict {
+						w.WriteHeader(putStatus)
+						w.Write([]byte(`{"error":"conflict","reason":"Document update conflict."}`))
+						return
+					}
+					w.Header().Set("Etag", `"3-c"`)
+					w.WriteHeader(putStatus)
+				case "POST":
+					if string(bs) != `{"docs":[{"_id":"x","_rev":"2-a","_deleted":true}]}` {
+						t.Errorf("Unexpected deletions %s", bs)
+					}
+					w.WriteHeader(http.StatusCreated)
+					w.Write([]byte(`[{"id":"x","rev":"3-d","ok":true}]`))
+				}
+			}))
+			defer srv.Close()
+
+			db := (&Client{Host: srv.URL, client: &http.Client{}}).Database("conflicts")
+			rev, err := db.ResolveConflicts(context.Background(), "x", func(id string, revs []json.RawMessage) (interface{}, error) {
+				return map[string]int{"n": 3}, nil
+			})
+			if putStatus == http.StatusConflict {
+				if !errors.Is(err, ErrConflict) || len(requests) != 2 {
+					t.Fatalf("Expected ErrConflict without deleting losers, got %v after %v", err, requests)
+				}
+				return
+			}
+			if err != nil || rev != "3-c" || len(requests) != 3 || requests[2] != "POST /conflicts/_bulk_docs" {
+				t.Fatalf("Expected winner to be written before deleting losers, got %q, %v after %v", rev, err, requests)
+			}
+		})
+	}
+}
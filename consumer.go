@@ -0,0 +1,196 @@
+package couchdb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// DefaultConsumerBatchSize is the number of changes a Consumer processes between two checkpoints
+const DefaultConsumerBatchSize = 100
+
+// CheckpointStore persists the position of changes feed consumers
+type CheckpointStore interface {
+	// Load returns the last sequence saved for the consumer, or an empty sequence if there is none
+	Load(ctx context.Context, id string) (Sequence, error)
+	// Save records seq as the position of the consumer
+	Save(ctx context.Context, id string, seq Sequence) error
+}
+
+// LocalCheckpointStore keeps checkpoints in _local documents, which are never replicated
+type LocalCheckpointStore struct {
+	DB *Database
+}
+
+type checkpointDocument struct {
+	Document
+	Seq Sequence `json:"seq"`
+}
+
+func checkpointID(id string) string {
+	return "_local/" + id
+}
+
+// Load fetches the checkpoint from GET /{db}/_local/{id}
+func (s LocalCheckpointStore) Load(ctx context.Context, id string) (Sequence, error) {
+	doc := checkpointDocument{}
+	err := s.DB.Get(ctx, checkpointID(id), &doc)
+	if errors.Is(err, ErrNotFound) {
+		return "", nil
+	}
+	return doc.Seq, err
+}
+
+// Save stores the checkpoint using PUT /{db}/_local/{id}
+func (s LocalCheckpointStore) Save(ctx context.Context, id string, seq Sequence) error {
+	doc := checkpointDocument{}
+	if err := s.DB.Get(ctx, checkpointID(id), &doc); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	doc.ID = checkpointID(id)
+	doc.Seq = seq
+	_, err := s.DB.Put(ctx, doc.ID, doc)
+	return err
+}
+
+// Consumer processes the changes of a database in batches, saving its position after every
+// batch. When restarted it resumes from the last checkpoint, so every change is handled at
+// least once: changes of a batch which did not complete are handled again.
+//
+//   consumer := couchdb.Consumer{
+//     DB:          db,
+//     ID:          "mailer",
+//     Concurrency: 4,
+//     Handler: func(ctx context.Context, change couchdb.Change) error {
+//       // …
+//     },
+//   }
+//   err := consumer.Run(ctx)
+type Consumer struct {
+	DB *Database
+	// ID identifies the checkpoint of the consumer
+	ID string
+	// Store persists checkpoints, defaulting to a LocalCheckpointStore on DB
+	Store CheckpointStore
+	// Handler is called for every change. Returning an error stops the consumer.
+	Handler func(context.Context, Change) error
+	// Opts configures the changes feed, e.g. to filter changes or include documents.
+	// Feed, Since and Limit are managed by the consumer.
+	Opts ChangesOpts
+	// BatchSize defaults to DefaultConsumerBatchSize
+	BatchSize int
+	// Concurrency is the number of changes handled in parallel, defaulting to 1
+	Concurrency int
+}
+
+// Run processes changes until the context is cancelled or the handler fails
+func (c *Consumer) Run(ctx context.Context) error {
+	if c.ID == "" || c.Handler == nil {
+		return errors.New("couchdb: consumer requires an ID and a Handler")
+	}
+	store := c.Store
+	if store == nil {
+		store = LocalCheckpointStore{DB: c.DB}
+	}
+	seq, err := store.Load(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+
+	for {
+		batch, last, err := c.poll(ctx, seq)
+		if err != nil {
+			return err
+		}
+		if err := c.handle(ctx, batch); err != nil {
+			return err
+		}
+		if last != "" && last != seq {
+			if err := store.Save(ctx, c.ID, last); err != nil {
+				return err
+			}
+			seq = last
+		}
+	}
+}
+
+// poll waits for the next batch of changes after seq, retrying while couchdb is unreachable
+func (c *Consumer) poll(ctx context.Context, seq Sequence) ([]Change, Sequence, error) {
+	opts := c.Opts
+	opts.Feed = FeedLongpoll
+	opts.Since = seq
+	opts.Limit = c.BatchSize
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultConsumerBatchSize
+	}
+
+	delay := minReconnectDelay
+	for {
+		feed, err := c.DB.Changes(ctx, opts)
+		if err == nil {
+			batch := []Change{}
+			for feed.Next() {
+				batch = append(batch, feed.Change())
+			}
+			feed.Close()
+			if err := feed.Err(); err != nil {
+				return nil, "", err
+			}
+			return batch, feed.LastSeq(), nil
+		}
+
+		var apiErr *Error
+		if errors.As(err, &apiErr) && apiErr.StatusCode < http.StatusInternalServerError {
+			return nil, "", err
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return nil, "", err
+		}
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// handle passes all changes to the handler, running at most Concurrency handlers at once
+func (c *Consumer) handle(ctx context.Context, batch []Change) error {
+	concurrency := c.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, concurrency)
+	for _, change := range batch {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(change Change) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := c.Handler(ctx, change); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(change)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
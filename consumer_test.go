@@ -0,0 +1,56 @@
+// +build !integration
+
+package couchdb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConsumer_Run(t *testing.T) {
+	t.Parallel()
+
+	db := client.Database("consumer-test")
+	client.Databases.Create(db.Name, DatabaseClusterOptions{})
+	defer client.Databases.Delete(db.Name)
+
+	db.Put(context.Background(), "employee:michael", testDoc{Name: "Michael"})
+	db.Put(context.Background(), "employee:raphael", testDoc{Name: "Raphael"})
+
+	var (
+		mu   sync.Mutex
+		seen = map[string]bool{}
+	)
+	// the consumer runs until the deadline, long after handling both changes
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	consumer := Consumer{
+		DB:          db,
+		ID:          "consumer-test",
+		Concurrency: 2,
+		Opts:        ChangesOpts{Timeout: time.Second},
+		Handler: func(ctx context.Context, change Change) error {
+			mu.Lock()
+			defer mu.Unlock()
+			seen[change.ID] = true
+			return nil
+		},
+	}
+	if err := consumer.Run(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal(err)
+	}
+	if !seen["employee:michael"] || !seen["employee:raphael"] {
+		t.Fatalf("Expected all changes to be handled, got %v", seen)
+	}
+
+	seq, err := LocalCheckpointStore{DB: db}.Load(context.Background(), consumer.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seq == "" {
+		t.Fatal("Expected checkpoint to be saved")
+	}
+}
@@ -28,5 +28,17 @@ func New(host string, client HTTPExecutor, configs ...func(*Client) error) (*Cli
 	c.Replications = &ReplicationService{c}
 	c.Sessions = &SessionService{c}
 	c.Cluster = &ClusterService{c}
-	return c, c.Check()
+	if err := c.Check(); err != nil {
+		return c, err
+	}
+	if c.discoverNodes {
+		if err := c.DiscoverNodes(); err != nil {
+			return c, err
+		}
+	}
+	if c.healthInterval > 0 && c.nodes != nil {
+		c.stopHealth = make(chan struct{})
+		go c.watchNodes(c.healthInterval, c.stopHealth)
+	}
+	return c, nil
 }
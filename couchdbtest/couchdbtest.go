@@ -0,0 +1,26 @@
+// Package couchdbtest provides helpers for tests of code using couchdb
+package couchdbtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nicolai86/couchdb-go"
+)
+
+// RequireIndex fails the test if couchdb would answer the query by scanning all documents
+// instead of using an index.
+//
+//   func TestEmployeeQueries(t *testing.T) {
+//     couchdbtest.RequireIndex(t, db, employeesByName)
+//   }
+func RequireIndex(t testing.TB, db *couchdb.Database, query couchdb.FindQuery) {
+	t.Helper()
+	explanation, err := db.Explain(context.Background(), query)
+	if err != nil {
+		t.Fatalf("couchdbtest: failed to explain query: %v", err)
+	}
+	if !explanation.UsesIndex() {
+		t.Fatalf("couchdbtest: query on %s is not served by an index: %s", db.Name, string(explanation.Selector))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 )
 
 // Database is a client for a specific couchdb server & database
@@ -12,9 +13,15 @@ type Database struct {
 	Name string
 }
 
-// Do forwards requests to the http client, prefixing the URL path with the database name
+// Do forwards requests to the http client, prefixing the URL path with the database name.
+// Escaped path segments of req, e.g. names containing slashes, are kept as they are.
 func (d *Database) Do(req *http.Request) (*http.Response, error) {
-	req.URL.Path = fmt.Sprintf("/%s%s", d.Name, req.URL.EscapedPath())
+	escaped := fmt.Sprintf("/%s%s", url.PathEscape(d.Name), req.URL.EscapedPath())
+	path, err := url.PathUnescape(escaped)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.Path, req.URL.RawPath = path, escaped
 	return d.c.Do(req)
 }
 
@@ -2,6 +2,7 @@ package couchdb
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -9,7 +10,8 @@ import (
 	"strconv"
 )
 
-// DatabaseService exposes database management apis
+// DatabaseService exposes database management apis. Database names are escaped the same way
+// Database does, so names containing slashes such as tenant/a address a single database.
 type DatabaseService struct {
 	c *Client
 }
@@ -28,7 +30,7 @@ type DatabaseClusterOptions struct {
 
 // Create creates a new database by calling PUT /{db}
 func (d *DatabaseService) Create(name string, opts DatabaseClusterOptions) error {
-	req, err := http.NewRequest("PUT", fmt.Sprintf("/%s", name), nil)
+	req, err := http.NewRequest("PUT", fmt.Sprintf("/%s", url.PathEscape(name)), nil)
 	if err != nil {
 		return err
 	}
@@ -53,7 +55,7 @@ func (d *DatabaseService) Create(name string, opts DatabaseClusterOptions) error
 
 // Delete removes a database
 func (d *DatabaseService) Delete(name string) error {
-	req, err := http.NewRequest("DELETE", fmt.Sprintf("/%s", name), nil)
+	req, err := http.NewRequest("DELETE", fmt.Sprintf("/%s", url.PathEscape(name)), nil)
 	if err != nil {
 		return err
 	}
@@ -76,7 +78,7 @@ type DatabaseMeta struct {
 
 // Meta looks up database metadata
 func (d *DatabaseService) Meta(name string) (DatabaseMeta, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("/%s", name), nil)
+	req, err := http.NewRequest("GET", fmt.Sprintf("/%s", url.PathEscape(name)), nil)
 	if err != nil {
 		return DatabaseMeta{}, err
 	}
@@ -96,11 +98,14 @@ func (d *DatabaseService) Meta(name string) (DatabaseMeta, error) {
 
 // Exists checks if the given database exists with a HEAD /{db} request
 func (d *DatabaseService) Exists(name string) (bool, error) {
-	req, err := http.NewRequest("HEAD", fmt.Sprintf("/%s", name), nil)
+	req, err := http.NewRequest("HEAD", fmt.Sprintf("/%s", url.PathEscape(name)), nil)
 	if err != nil {
 		return false, err
 	}
 	resp, err := d.c.Do(req)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
 	if err != nil {
 		return false, err
 	}
@@ -2,7 +2,13 @@
 
 package couchdb
 
-import "testing"
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
 
 func TestDatabase_NotExisting(t *testing.T) {
 	t.Parallel()
@@ -30,6 +36,50 @@ func TestDatabase_Exists(t *testing.T) {
 	}
 }
 
+func TestDatabaseService_NameEscaping(t *testing.T) {
+	t.Parallel()
+
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.EscapedPath())
+		w.Write([]byte(`{"ok":true,"db_name":"tenant/a"}`))
+	}))
+	defer srv.Close()
+	c := &Client{Host: srv.URL, client: &http.Client{}}
+	c.Databases = &DatabaseService{c: c}
+
+	if err := c.Databases.Create("tenant/a", DatabaseClusterOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Databases.Exists("tenant/a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Databases.Meta("tenant/a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Database("tenant/a").Get(context.Background(), "doc", &Document{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Databases.Delete("tenant/a"); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"PUT /tenant%2Fa",
+		"HEAD /tenant%2Fa",
+		"GET /tenant%2Fa",
+		"GET /tenant%2Fa/doc",
+		"DELETE /tenant%2Fa",
+	}
+	if len(paths) != len(expected) {
+		t.Fatalf("Expected requests %v, got %v", expected, paths)
+	}
+	for i := range expected {
+		if paths[i] != expected[i] {
+			t.Fatalf("Expected requests %v, got %v", expected, paths)
+		}
+	}
+}
+
 func TestClient_Create(t *testing.T) {
 	if err := client.Databases.Create("new-db", DatabaseClusterOptions{}); err != nil {
 		t.Fatal(err)
@@ -41,3 +91,32 @@ func TestClient_Delete(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestClient_DBUpdates(t *testing.T) {
+	if !client.CouchDB.HasClusterSupport() {
+		t.Skip("_db_updates requires couchdb 2.x")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	feed, err := client.DBUpdates(ctx, DBUpdatesOpts{
+		Feed:      FeedContinuous,
+		Since:     SinceNow,
+		Heartbeat: time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer feed.Close()
+
+	client.Databases.Create("db-updates-test", DatabaseClusterOptions{})
+	defer client.Databases.Delete("db-updates-test")
+
+	for feed.Next() {
+		update := feed.Update()
+		if update.DBName == "db-updates-test" && update.Type == DBCreated {
+			return
+		}
+	}
+	t.Fatalf("Expected database creation to be reported, got %v", feed.Err())
+}
@@ -0,0 +1,127 @@
+package couchdb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Types of database updates reported by DBUpdates
+const (
+	DBCreated = "created"
+	DBUpdated = "updated"
+	DBDeleted = "deleted"
+)
+
+// DBUpdatesOpts defines parameters for watching database updates of a couchdb server
+type DBUpdatesOpts struct {
+	// Feed is one of FeedNormal, FeedLongpoll, FeedContinuous or FeedEventSource, defaulting to FeedLongpoll
+	Feed string
+	// Since starts the feed after the given sequence, or at SinceNow
+	Since Sequence
+	// Heartbeat makes couchdb send empty lines while no updates occur, keeping the connection alive
+	Heartbeat time.Duration
+	// Timeout ends a response if no updates occurred in time
+	Timeout time.Duration
+}
+
+// DBUpdate describes the creation, update or deletion of a database
+type DBUpdate struct {
+	DBName string   `json:"db_name"`
+	Type   string   `json:"type"`
+	Seq    Sequence `json:"seq"`
+}
+
+// DBUpdatesFeed iterates over database updates. Normal and longpoll feeds end after a single
+// response; continuous and eventsource feeds run until the context is cancelled or the feed is
+// closed. After network errors the feed reconnects, resuming after the last update seen.
+type DBUpdatesFeed struct {
+	feed   *feed
+	update DBUpdate
+	err    error
+}
+
+// DBUpdates watches all databases of the server for changes. GET /_db_updates
+func (c *Client) DBUpdates(ctx context.Context, opts DBUpdatesOpts) (*DBUpdatesFeed, error) {
+	if opts.Feed == "" {
+		opts.Feed = FeedLongpoll
+	}
+	follow := opts.Feed == FeedContinuous || opts.Feed == FeedEventSource
+	f, err := newFeed(ctx, opts.Feed, follow, opts.Since, func(ctx context.Context, since Sequence) (*http.Response, error) {
+		return c.dbUpdates(ctx, opts, since)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &DBUpdatesFeed{feed: f}, nil
+}
+
+func (c *Client) dbUpdates(ctx context.Context, opts DBUpdatesOpts, since Sequence) (*http.Response, error) {
+	req, err := http.NewRequest("GET", "/_db_updates", nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	values := req.URL.Query()
+	values.Set("feed", opts.Feed)
+	if since != "" {
+		values.Set("since", string(since))
+	}
+	if opts.Heartbeat > 0 {
+		values.Set("heartbeat", strconv.FormatInt(int64(opts.Heartbeat/time.Millisecond), 10))
+	}
+	if opts.Timeout > 0 {
+		values.Set("timeout", strconv.FormatInt(int64(opts.Timeout/time.Millisecond), 10))
+	}
+	req.URL.RawQuery = values.Encode()
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, newError(req, resp, nil)
+	}
+	return resp, nil
+}
+
+// Next advances to the next update, returning false once the feed ended or failed
+func (f *DBUpdatesFeed) Next() bool {
+	if f.err != nil || !f.feed.next() {
+		return false
+	}
+	update := DBUpdate{}
+	if err := json.Unmarshal(f.feed.event, &update); err != nil {
+		f.err = err
+		return false
+	}
+	f.update = update
+	return true
+}
+
+// Update returns the current update
+func (f *DBUpdatesFeed) Update() DBUpdate {
+	return f.update
+}
+
+// LastSeq returns the sequence of the last update seen, or the last sequence reported by couchdb
+func (f *DBUpdatesFeed) LastSeq() Sequence {
+	return f.feed.lastSeq
+}
+
+// Err returns the error which ended the feed, if any
+func (f *DBUpdatesFeed) Err() error {
+	if f.err != nil {
+		return f.err
+	}
+	return f.feed.err
+}
+
+// Close stops the feed. It is safe to call Close while another goroutine is waiting in Next.
+func (f *DBUpdatesFeed) Close() error {
+	return f.feed.close()
+}
@@ -0,0 +1,458 @@
+package couchdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const designPrefix = "_design/"
+
+// Actions taken when deploying design documents
+const (
+	// DesignCreated marks design documents which were not deployed yet
+	DesignCreated = "created"
+	// DesignUpdated marks design documents which differ from the deployed version
+	DesignUpdated = "updated"
+	// DesignUnchanged marks design documents matching the deployed version
+	DesignUnchanged = "unchanged"
+)
+
+// LoadDesignDocument reads a design document from a couchapp style directory named after the
+// design document. All entries are optional:
+//
+//   employees/
+//     language              defaults to javascript
+//     autoupdate            true or false
+//     options.json
+//     validate_doc_update.js
+//     rewrites.json         or rewrites.js
+//     views/
+//       by_name/
+//         map.js            or map.json for Mango views
+//         reduce.js
+//         options.json
+//     filters/<name>.js
+//     updates/<name>.js
+//     shows/<name>.js
+//     lists/<name>.js
+func LoadDesignDocument(dir string) (*DesignDocument, error) {
+	name := filepath.Base(dir)
+	ddoc := &DesignDocument{
+		Document: Document{ID: designPrefix + name},
+		Language: "javascript",
+		Views:    map[string]View{},
+	}
+
+	language, ok, err := readDesignFile(filepath.Join(dir, "language"))
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		ddoc.Language = language
+	}
+	autoupdate, ok, err := readDesignFile(filepath.Join(dir, "autoupdate"))
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		enabled, err := strconv.ParseBool(autoupdate)
+		if err != nil {
+			return nil, fmt.Errorf("couchdb: invalid autoupdate of %s: %v", name, err)
+		}
+		ddoc.Autoupdate = &enabled
+	}
+	if _, err := readDesignJSON(filepath.Join(dir, "options.json"), &ddoc.Options); err != nil {
+		return nil, err
+	}
+	if ddoc.ValidateDocUpdate, _, err = readDesignFile(filepath.Join(dir, "validate_doc_update.js")); err != nil {
+		return nil, err
+	}
+	if ok, err = readDesignJSON(filepath.Join(dir, "rewrites.json"), &ddoc.Rewrites); err != nil {
+		return nil, err
+	}
+	if !ok {
+		rewrites, ok, err := readDesignFile(filepath.Join(dir, "rewrites.js"))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			ddoc.Rewrites = rewrites
+		}
+	}
+
+	views, err := designEntries(filepath.Join(dir, "views"), true)
+	if err != nil {
+		return nil, err
+	}
+	for _, view := range views {
+		v, err := loadView(filepath.Join(dir, "views", view))
+		if err != nil {
+			return nil, fmt.Errorf("couchdb: view %s/%s: %v", name, view, err)
+		}
+		ddoc.Views[view] = *v
+	}
+
+	functions := map[string]*map[string]string{
+		"filters": &ddoc.Filters,
+		"updates": &ddoc.Updates,
+		"shows":   &ddoc.Shows,
+		"lists":   &ddoc.Lists,
+	}
+	for kind, target := range functions {
+		if *target, err = readDesignFunctions(filepath.Join(dir, kind)); err != nil {
+			return nil, err
+		}
+	}
+	return ddoc, nil
+}
+
+// loadView reads the map function, or Mango index definition, and reduce function of a view
+func loadView(dir string) (*View, error) {
+	v := &View{}
+	mapFn, ok, err := readDesignFile(filepath.Join(dir, "map.js"))
+	if err != nil {
+		return nil, err
+	}
+	v.MapFn = mapFn
+	if !ok {
+		if ok, err = readDesignJSON(filepath.Join(dir, "map.json"), &v.Map); err != nil {
+			return nil, err
+		}
+	}
+	if !ok {
+		return nil, errors.New("neither map.js nor map.json found")
+	}
+	if v.ReduceFn, _, err = readDesignFile(filepath.Join(dir, "reduce.js")); err != nil {
+		return nil, err
+	}
+	if _, err := readDesignJSON(filepath.Join(dir, "options.json"), &v.Options); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// readDesignFunctions reads all *.js files in dir, keyed by their name without extension.
+// It returns nil if there are none.
+func readDesignFunctions(dir string) (map[string]string, error) {
+	files, err := designEntries(dir, false)
+	if err != nil {
+		return nil, err
+	}
+	var functions map[string]string
+	for _, file := range files {
+		if filepath.Ext(file) != ".js" {
+			continue
+		}
+		fn, _, err := readDesignFile(filepath.Join(dir, file))
+		if err != nil {
+			return nil, err
+		}
+		if functions == nil {
+			functions = map[string]string{}
+		}
+		functions[strings.TrimSuffix(file, ".js")] = fn
+	}
+	return functions, nil
+}
+
+// readDesignJSON decodes a JSON file into v, reporting whether it exists
+func readDesignJSON(path string, v interface{}) (bool, error) {
+	bs, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(bs, v); err != nil {
+		return false, fmt.Errorf("couchdb: invalid %s: %v", path, err)
+	}
+	return true, nil
+}
+
+// LoadDesignDocuments reads every subdirectory of root as design document using LoadDesignDocument
+func LoadDesignDocuments(root string) ([]DesignDocument, error) {
+	names, err := designEntries(root, true)
+	if err != nil {
+		return nil, err
+	}
+	ddocs := []DesignDocument{}
+	for _, name := range names {
+		ddoc, err := LoadDesignDocument(filepath.Join(root, name))
+		if err != nil {
+			return nil, err
+		}
+		ddocs = append(ddocs, *ddoc)
+	}
+	return ddocs, nil
+}
+
+// designEntries lists the names of the directories, or files, in dir. Missing directories are empty.
+func designEntries(dir string, dirs bool) ([]string, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, info := range infos {
+		if info.IsDir() == dirs && !strings.HasPrefix(info.Name(), ".") {
+			names = append(names, info.Name())
+		}
+	}
+	return names, nil
+}
+
+// readDesignFile returns the trimmed content of a file, reporting whether it exists
+func readDesignFile(path string) (string, bool, error) {
+	bs, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return strings.TrimSpace(string(bs)), true, nil
+}
+
+// DesignChange describes the difference between a design document and its deployed version
+type DesignChange struct {
+	ID string
+	// Action is one of DesignCreated, DesignUpdated or DesignUnchanged
+	Action string
+	// Rev is the revision deployed before, if any
+	Rev string
+}
+
+// DiffDesign compares design documents with the deployed versions, ignoring revisions
+func (d *Database) DiffDesign(ctx context.Context, ddocs []DesignDocument) ([]DesignChange, error) {
+	changes := []DesignChange{}
+	for _, ddoc := range ddocs {
+		ddoc = normalizeDesign(ddoc)
+		deployed := DesignDocument{}
+		err := d.Get(ctx, ddoc.ID, &deployed)
+		if errors.Is(err, ErrNotFound) {
+			changes = append(changes, DesignChange{ID: ddoc.ID, Action: DesignCreated})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		same, err := sameDesign(deployed, ddoc)
+		if err != nil {
+			return nil, err
+		}
+		change := DesignChange{ID: ddoc.ID, Action: DesignUpdated, Rev: deployed.Rev}
+		if same {
+			change.Action = DesignUnchanged
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// DeployOpts defines how design documents are deployed
+type DeployOpts struct {
+	// Swap uploads changed design documents as _design/{name}_new first, waits for couchdb to
+	// build their views and then copies them over the live version, so queries never wait for
+	// the views to be rebuilt
+	Swap bool
+}
+
+// DeployDesign uploads design documents which differ from the deployed versions, returning the
+// changes applied. Use the context to bound the time spent waiting for views when swapping.
+//
+//   ddocs, err := couchdb.LoadDesignDocuments("./design")
+//   if err != nil {
+//     return err
+//   }
+//   changes, err := db.DeployDesign(ctx, ddocs, couchdb.DeployOpts{Swap: true})
+func (d *Database) DeployDesign(ctx context.Context, ddocs []DesignDocument, opts DeployOpts) ([]DesignChange, error) {
+	changes, err := d.DiffDesign(ctx, ddocs)
+	if err != nil {
+		return nil, err
+	}
+	for i, change := range changes {
+		ddoc := normalizeDesign(ddocs[i])
+		switch {
+		case change.Action == DesignUnchanged:
+		case change.Action == DesignUpdated && opts.Swap:
+			err = d.swapDesign(ctx, ddoc, change.Rev)
+		default:
+			ddoc.Rev = change.Rev
+			_, err = d.Put(ctx, ddoc.ID, ddoc)
+		}
+		if err != nil {
+			return changes[:i], err
+		}
+	}
+	return changes, nil
+}
+
+// swapDesign replaces the live design document once the views of its new version are built
+func (d *Database) swapDesign(ctx context.Context, ddoc DesignDocument, rev string) error {
+	live := ddoc.ID
+	staged := live + "_new"
+
+	stagedRev, err := d.Rev(ctx, staged)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	ddoc.ID, ddoc.Rev = staged, stagedRev
+	if stagedRev, err = d.Put(ctx, staged, ddoc); err != nil {
+		return err
+	}
+	if err := d.WarmUp(ctx, strings.TrimPrefix(staged, designPrefix), nil); err != nil {
+		return err
+	}
+	if _, err := d.Copy(ctx, staged, live, rev); err != nil {
+		return err
+	}
+	_, err = d.Delete(ctx, staged, stagedRev)
+	return err
+}
+
+// normalizeDesign completes design documents the way couchdb would
+func normalizeDesign(ddoc DesignDocument) DesignDocument {
+	if !strings.HasPrefix(ddoc.ID, designPrefix) {
+		ddoc.ID = designPrefix + ddoc.ID
+	}
+	if ddoc.Language == "" {
+		ddoc.Language = "javascript"
+	}
+	return ddoc
+}
+
+// sameDesign compares the content of design documents, ignoring revisions
+func sameDesign(a, b DesignDocument) (bool, error) {
+	a.Document, b.Document = Document{}, Document{}
+	left, err := normalizeJSON(a)
+	if err != nil {
+		return false, err
+	}
+	right, err := normalizeJSON(b)
+	if err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(left, right), nil
+}
+
+// Copy copies a document, returning the revision of the copy. destRev must be set to the
+// current revision if the destination exists. COPY /{db}/{id}
+func (d *Database) Copy(ctx context.Context, id, dest, destRev string) (string, error) {
+	req, _ := http.NewRequest("COPY", fmt.Sprintf("/%s", id), nil)
+	req = req.WithContext(ctx)
+	if destRev != "" {
+		dest = fmt.Sprintf("%s?rev=%s", dest, destRev)
+	}
+	req.Header.Set("Destination", dest)
+
+	resp, err := d.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusAccepted {
+		return "", newError(req, resp, nil)
+	}
+	return revision(resp.Header.Get("Etag")), nil
+}
+
+// DesignResponse is the response of a show or list function. It must be closed after reading.
+type DesignResponse struct {
+	io.ReadCloser
+	ContentType string
+}
+
+// CallUpdate invokes an update handler, sending payload as JSON body and decoding the JSON
+// response into result, if not nil. Without docID the handler is called with a null document.
+// It returns the revision written by the handler, if any.
+// PUT /{db}/_design/{design}/_update/{fn}/{docID}, or POST /{db}/_design/{design}/_update/{fn}
+//
+//   var result struct{ Status string }
+//   rev, err := db.CallUpdate(ctx, "company", "promote", "employee:michael", promotion{Title: "CTO"}, &result)
+func (d *Database) CallUpdate(ctx context.Context, design, fn, docID string, payload, result interface{}) (string, error) {
+	bs, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	method, path := "POST", fmt.Sprintf("/_design/%s/_update/%s", design, fn)
+	if docID != "" {
+		method, path = "PUT", fmt.Sprintf("%s/%s", path, docID)
+	}
+	req, err := http.NewRequest(method, path, bytes.NewReader(bs))
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+	resp, err := d.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusAccepted {
+		return "", newError(req, resp, nil)
+	}
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return "", err
+		}
+	}
+	return resp.Header.Get("X-Couch-Update-NewRev"), nil
+}
+
+// CallShow renders a document using a show function, passing params as req.query. Without
+// docID the function is called with a null document.
+// GET /{db}/_design/{design}/_show/{fn}/{docID}
+func (d *Database) CallShow(ctx context.Context, design, fn, docID string, params url.Values) (*DesignResponse, error) {
+	path := fmt.Sprintf("/_design/%s/_show/%s", design, fn)
+	if docID != "" {
+		path = fmt.Sprintf("%s/%s", path, docID)
+	}
+	req, err := http.NewRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = params.Encode()
+	return d.render(req.WithContext(ctx))
+}
+
+// CallList renders the results of a view using a list function. The view is either a view of
+// the same design document or named {design}/{view}.
+// GET /{db}/_design/{design}/_list/{fn}/{view}
+func (d *Database) CallList(ctx context.Context, design, fn, view string, opts ViewOpts) (*DesignResponse, error) {
+	req, err := d.viewRequest(ctx, fmt.Sprintf("/_design/%s/_list/%s/%s", design, fn, view), opts)
+	if err != nil {
+		return nil, err
+	}
+	return d.render(req)
+}
+
+func (d *Database) render(req *http.Request) (*DesignResponse, error) {
+	resp, err := d.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, newError(req, resp, nil)
+	}
+	return &DesignResponse{
+		ReadCloser:  resp.Body,
+		ContentType: resp.Header.Get("Content-Type"),
+	}, nil
+}
@@ -0,0 +1,173 @@
+// +build !integration
+
+package couchdb
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadDesignDocuments(t *testing.T) {
+	t.Parallel()
+
+	root, err := ioutil.TempDir("", "design")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	view := filepath.Join(root, "company", "views", "by_name")
+	if err := os.MkdirAll(view, 0755); err != nil {
+		t.Fatal(err)
+	}
+	ioutil.WriteFile(filepath.Join(view, "map.js"), []byte("function(doc) { emit(doc.name) }\n"), 0644)
+	ioutil.WriteFile(filepath.Join(view, "reduce.js"), []byte("_count\n"), 0644)
+	filters := filepath.Join(root, "company", "filters")
+	if err := os.MkdirAll(filters, 0755); err != nil {
+		t.Fatal(err)
+	}
+	ioutil.WriteFile(filepath.Join(filters, "employees.js"), []byte("function(doc) { return doc.type == 'employee' }"), 0644)
+	ioutil.WriteFile(filepath.Join(root, "company", "options.json"), []byte(`{"local_seq":true}`), 0644)
+
+	ddocs, err := LoadDesignDocuments(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ddocs) != 1 || ddocs[0].ID != "_design/company" || ddocs[0].Language != "javascript" {
+		t.Fatalf("Unexpected design documents %#v", ddocs)
+	}
+	if fn := ddocs[0].Views["by_name"]; fn.MapFn != "function(doc) { emit(doc.name) }" || fn.ReduceFn != "_count" {
+		t.Fatalf("Unexpected view %#v", fn)
+	}
+	if ddocs[0].Filters["employees"] == "" || ddocs[0].Options == nil || !ddocs[0].Options.LocalSeq {
+		t.Fatalf("Expected filters and options to be loaded, got %#v", ddocs[0])
+	}
+}
+
+func TestView_JSON(t *testing.T) {
+	t.Parallel()
+
+	for _, expected := range []string{
+		`{"map":"function(doc) { emit(doc.name) }","reduce":"_count"}`,
+		`{"map":{"fields":{"name":"asc"}},"reduce":"_count","options":{"def":{"fields":["name"]}}}`,
+	} {
+		v := View{}
+		if err := json.Unmarshal([]byte(expected), &v); err != nil {
+			t.Fatal(err)
+		}
+		bs, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(bs) != expected {
+			t.Fatalf("Expected %s, got %s", expected, bs)
+		}
+	}
+}
+
+func TestDatabase_DeployDesign(t *testing.T) {
+	t.Parallel()
+
+	db := client.Database("design-test")
+	client.Databases.Create(db.Name, DatabaseClusterOptions{})
+	defer client.Databases.Delete(db.Name)
+
+	ddoc := DesignDocument{
+		Document: Document{ID: "employees"},
+		Views: map[string]View{
+			"by_name": {MapFn: "function(doc) { emit(doc.name) }"},
+		},
+	}
+	deploy := func(ddoc DesignDocument) string {
+		changes, err := db.DeployDesign(context.Background(), []DesignDocument{ddoc}, DeployOpts{Swap: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return changes[0].Action
+	}
+
+	if action := deploy(ddoc); action != DesignCreated {
+		t.Fatalf("Expected %q, got %q", DesignCreated, action)
+	}
+	if action := deploy(ddoc); action != DesignUnchanged {
+		t.Fatalf("Expected %q, got %q", DesignUnchanged, action)
+	}
+	ddoc.Views["count"] = View{MapFn: "function(doc) { emit(null) }", ReduceFn: "_count"}
+	if action := deploy(ddoc); action != DesignUpdated {
+		t.Fatalf("Expected %q, got %q", DesignUpdated, action)
+	}
+
+	deployed := DesignDocument{}
+	if err := db.Get(context.Background(), "_design/employees", &deployed); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := deployed.Views["count"]; !ok {
+		t.Fatal("Expected updated design document to be live")
+	}
+	if _, err := db.Rev(context.Background(), "_design/employees_new"); err == nil {
+		t.Fatal("Expected staged design document to be removed")
+	}
+}
+
+func TestDatabase_DesignFunctions(t *testing.T) {
+	t.Parallel()
+
+	db := client.Database("design-functions-test")
+	client.Databases.Create(db.Name, DatabaseClusterOptions{})
+	defer client.Databases.Delete(db.Name)
+
+	if _, err := db.DeployDesign(context.Background(), []DesignDocument{{
+		Document: Document{ID: "company"},
+		Views: map[string]View{
+			"names": {MapFn: "function(doc) { emit(doc.name) }"},
+		},
+		Updates: map[string]string{
+			"rename": `function(doc, req) {
+  var body = JSON.parse(req.body);
+  doc = doc || {_id: req.id};
+  doc.name = body.name;
+  return [doc, JSON.stringify({name: doc.name})];
+}`,
+		},
+		Shows: map[string]string{
+			"name": "function(doc, req) { return doc.name }",
+		},
+		Lists: map[string]string{
+			"names": "function(head, req) { var row; while (row = getRow()) { send(row.key + '\\n') } }",
+		},
+	}}, DeployOpts{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var result struct {
+		Name string `json:"name"`
+	}
+	rev, err := db.CallUpdate(context.Background(), "company", "rename", "employee:michael", map[string]string{"name": "Michael"}, &result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev == "" || result.Name != "Michael" {
+		t.Fatalf("Unexpected update result %q: %#v", rev, result)
+	}
+
+	render := func(resp *DesignResponse, err error) string {
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Close()
+		bs, err := ioutil.ReadAll(resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(bs)
+	}
+	if body := render(db.CallShow(context.Background(), "company", "name", "employee:michael", nil)); body != "Michael" {
+		t.Fatalf("Expected show to render %q, got %q", "Michael", body)
+	}
+	if body := render(db.CallList(context.Background(), "company", "names", "names", ViewOpts{})); body != "Michael\n" {
+		t.Fatalf("Expected list to render %q, got %q", "Michael\n", body)
+	}
+}
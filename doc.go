@@ -4,21 +4,31 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"strconv"
+	"net/url"
 )
 
-// ErrNotFound is a reusable and checkable 404 error
-var ErrNotFound = errors.New("Given document ID was not found in couchDB")
-
 // Document contains basic document identifications
 type Document struct {
-	ID      string `json:"_id,omitempty"`
-	Rev     string `json:"_rev,omitempty"`
-	Deleted *bool  `json:"_deleted,omitempty"`
+	ID          string                `json:"_id,omitempty"`
+	Rev         string                `json:"_rev,omitempty"`
+	Deleted     *bool                 `json:"_deleted,omitempty"`
+	Attachments map[string]Attachment `json:"_attachments,omitempty"`
+
+	// Conflicts, DeletedConflicts and RevsInfo are only returned if requested using GetOpts.
+	// couchdb ignores them when writing documents.
+	Conflicts        []string  `json:"_conflicts,omitempty"`
+	DeletedConflicts []string  `json:"_deleted_conflicts,omitempty"`
+	RevsInfo         []RevInfo `json:"_revs_info,omitempty"`
+}
+
+// RevInfo describes a revision in the history of a document
+type RevInfo struct {
+	Rev string `json:"rev"`
+	// Status is one of available, missing or deleted
+	Status string `json:"status"`
 }
 
 func revision(etag string) string {
@@ -29,32 +39,21 @@ func revision(etag string) string {
 }
 
 // AllDocOpts defines parameters which can be passed to APIs returning multiple documents
-type AllDocOpts struct {
-	Skip        int
-	Limit       int
-	IncludeDocs bool
-	StartKey    string
-	EndKey      string
-}
+//
+// Deprecated: use ViewOpts, which AllDocOpts is an alias of
+type AllDocOpts = ViewOpts
 
-func (d *Database) bulkGet(ctx context.Context, path string, opts AllDocOpts, results interface{}) error {
-	req, _ := http.NewRequest("GET", path, nil)
-	req = req.WithContext(ctx)
+type keysRequest struct {
+	Keys []interface{} `json:"keys"`
+}
 
-	values := req.URL.Query()
-	if opts.Limit == 0 {
-		opts.Limit = 100
-	}
-	values.Set("skip", strconv.Itoa(opts.Skip))
-	values.Set("limit", strconv.Itoa(opts.Limit))
-	values.Set("include_docs", strconv.FormatBool(opts.IncludeDocs))
-	if opts.StartKey != "" {
-		values.Set("startkey", fmt.Sprintf("%q", opts.StartKey))
-	}
-	if opts.EndKey != "" {
-		values.Set("endkey", fmt.Sprintf("%q", opts.EndKey))
+// bulkGet queries a view like endpoint. If opts contains keys, only rows matching them are
+// requested using POST.
+func (d *Database) bulkGet(ctx context.Context, path string, opts ViewOpts, results interface{}) error {
+	req, err := d.viewRequest(ctx, path, opts)
+	if err != nil {
+		return err
 	}
-	req.URL.RawQuery = values.Encode()
 
 	resp, err := d.Do(req)
 	if err != nil {
@@ -63,26 +62,56 @@ func (d *Database) bulkGet(ctx context.Context, path string, opts AllDocOpts, re
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusNotFound {
-			return ErrNotFound
-		}
-		return fmt.Errorf("couchdb: GET %s returned %d", req.URL.Path, resp.StatusCode)
+		return newError(req, resp, nil)
 	}
 
-	data, err := ioutil.ReadAll(resp.Body)
+	return json.NewDecoder(resp.Body).Decode(&results)
+}
+
+// viewRequest builds a request querying a view like endpoint
+func (d *Database) viewRequest(ctx context.Context, path string, opts ViewOpts) (*http.Request, error) {
+	method, body := "GET", []byte{}
+	// keys are sent as body, as they may not fit into the URL
+	if opts.Keys != nil {
+		bs, err := json.Marshal(keysRequest{opts.Keys})
+		if err != nil {
+			return nil, err
+		}
+		method, body = "POST", bs
+	}
+	req, err := http.NewRequest(method, path, bytes.NewReader(body))
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	if err := json.Unmarshal(data, &results); err != nil {
-		return err
+	values, err := opts.values()
+	if err != nil {
+		return nil, err
 	}
-
-	return nil
+	req.URL.RawQuery = values.Encode()
+	return req.WithContext(ctx), nil
 }
 
 // AllDocs fetches all documents from couchdb
-func (d *Database) AllDocs(ctx context.Context, opts AllDocOpts, results interface{}) error {
+func (d *Database) AllDocs(ctx context.Context, opts ViewOpts, results interface{}) error {
+	return d.bulkGet(ctx, "/_all_docs", opts, results)
+}
+
+// AllDocsKeys fetches the documents with the given IDs. POST /{db}/_all_docs
+// IDs which do not exist are reported as rows carrying an error instead of failing the request:
+//
+//   type results struct {
+//     couchdb.Results
+//     Rows []struct {
+//       Key   string            `json:"key"`
+//       Error string            `json:"error"`
+//       Doc   *couchdb.Document `json:"doc"`
+//     } `json:"rows"`
+//   }
+func (d *Database) AllDocsKeys(ctx context.Context, keys []string, opts ViewOpts, results interface{}) error {
+	opts.Keys = make([]interface{}, len(keys))
+	for i, key := range keys {
+		opts.Keys[i] = key
+	}
 	return d.bulkGet(ctx, "/_all_docs", opts, results)
 }
 
@@ -103,19 +132,61 @@ type DocumentReader interface {
 //  var doc couchdb.Document
 //  db.Get("some-id", &doc)
 func (d *Database) Get(ctx context.Context, id string, doc interface{}) error {
+	return d.GetWithOpts(ctx, id, GetOpts{}, doc)
+}
+
+// GetOpts defines parameters for fetching a single document
+type GetOpts struct {
+	// Rev fetches a specific revision instead of the winning one
+	Rev string
+	// Latest fetches the latest leaf revision of the branch Rev belongs to
+	Latest bool
+	// Conflicts lists conflicting leaf revisions in Document.Conflicts
+	Conflicts bool
+	// DeletedConflicts lists deleted conflicting revisions in Document.DeletedConflicts
+	DeletedConflicts bool
+	// RevsInfo lists the revision history in Document.RevsInfo
+	RevsInfo bool
+}
+
+func (opts GetOpts) values() url.Values {
+	values := url.Values{}
+	if opts.Rev != "" {
+		values.Set("rev", opts.Rev)
+	}
+	flags := map[string]bool{
+		"latest":            opts.Latest,
+		"conflicts":         opts.Conflicts,
+		"deleted_conflicts": opts.DeletedConflicts,
+		"revs_info":         opts.RevsInfo,
+	}
+	for name, set := range flags {
+		if set {
+			values.Set(name, "true")
+		}
+	}
+	return values
+}
+
+// GetWithOpts fetches a document like Get, allowing to request specific revisions and
+// revision metadata. GET /{db}/{id}
+//
+//  var doc couchdb.Document
+//  db.GetWithOpts(ctx, "some-id", couchdb.GetOpts{Conflicts: true}, &doc)
+//  if len(doc.Conflicts) > 0 {
+//    // …
+//  }
+func (d *Database) GetWithOpts(ctx context.Context, id string, opts GetOpts, doc interface{}) error {
 	req, _ := http.NewRequest("GET", fmt.Sprintf("/%s", id), nil)
 	req = req.WithContext(ctx)
+	req.URL.RawQuery = opts.values().Encode()
 	resp, err := d.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusNotFound {
-			return ErrNotFound
-		}
-
-		return fmt.Errorf("couchdb: GET %s returned %d", id, resp.StatusCode)
+		return newError(req, resp, nil)
 	}
 	data, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
@@ -156,7 +227,7 @@ func (d *Database) Put(ctx context.Context, id string, doc interface{}) (string,
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("couchdb: PUT %s returned %d", id, resp.StatusCode)
+		return "", newError(req, resp, nil)
 	}
 	return revision(resp.Header.Get("Etag")), nil
 }
@@ -175,7 +246,7 @@ func (d *Database) Delete(ctx context.Context, id, rev string) (string, error) {
 	}
 	resp.Body.Close()
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
-		return "", fmt.Errorf("couchdb: DELETE %s returned %d", id, resp.StatusCode)
+		return "", newError(req, resp, nil)
 	}
 	return revision(resp.Header.Get("Etag")), nil
 }
@@ -190,12 +261,8 @@ func (d *Database) Rev(ctx context.Context, id string) (string, error) {
 	}
 	resp.Body.Close()
 
-	if resp.StatusCode == http.StatusNotFound {
-		return "", ErrNotFound
-	}
-
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("couchdb: HEAD %s returned %d", id, resp.StatusCode)
+		return "", newError(req, resp, nil)
 	}
 	return revision(resp.Header.Get("Etag")), nil
 }
@@ -207,3 +207,30 @@ func TestDatabase_Rev(t *testing.T) {
 		}
 	})
 }
+
+func TestDatabase_AllDocsKeys(t *testing.T) {
+	t.Parallel()
+
+	var results struct {
+		Results
+		Rows []struct {
+			Key   string   `json:"key"`
+			Error string   `json:"error"`
+			Doc   *testDoc `json:"doc"`
+		} `json:"rows"`
+	}
+	if err := playground.AllDocsKeys(context.Background(), []string{"pet:yumi", "pet:unknown"}, AllDocOpts{
+		IncludeDocs: true,
+	}, &results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results.Rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(results.Rows))
+	}
+	if results.Rows[0].Doc == nil || results.Rows[0].Doc.Name != "Yumi" {
+		t.Fatal("Expected doc to be included")
+	}
+	if results.Rows[1].Error != "not_found" {
+		t.Fatalf("Expected missing doc to be reported, got %q", results.Rows[1].Error)
+	}
+}
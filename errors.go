@@ -0,0 +1,99 @@
+package couchdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Sentinel errors matching failed couchdb API requests. Use errors.Is to check for them:
+//
+//   if errors.Is(err, couchdb.ErrConflict) {
+//     // refetch the document and try again
+//   }
+var (
+	// ErrNotFound is a reusable and checkable 404 error
+	ErrNotFound = errors.New("Given document ID was not found in couchDB")
+	// ErrDatabaseNotFound matches 404 errors caused by a missing database
+	ErrDatabaseNotFound = errors.New("couchdb: database does not exist")
+	// ErrBadRequest matches 400 errors
+	ErrBadRequest = errors.New("couchdb: bad request")
+	// ErrUnauthorized matches 401 errors
+	ErrUnauthorized = errors.New("couchdb: unauthorized")
+	// ErrForbidden matches 403 errors, e.g. raised by validate_doc_update functions
+	ErrForbidden = errors.New("couchdb: forbidden")
+	// ErrConflict matches 409 errors caused by updating a document with an outdated revision
+	ErrConflict = errors.New("couchdb: document update conflict")
+	// ErrPreconditionFailed matches 412 errors, e.g. when creating an existing database
+	ErrPreconditionFailed = errors.New("couchdb: precondition failed")
+	// ErrTooLarge matches 413 errors caused by documents or requests exceeding couchdb's limits
+	ErrTooLarge = errors.New("couchdb: request entity too large")
+)
+
+// Error describes a failed couchdb API request
+type Error struct {
+	ErrorResponse
+	StatusCode int
+	Method     string
+	Path       string
+	RequestID  string
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("couchdb: %s %s returned %d", e.Method, e.Path, e.StatusCode)
+	if e.Type != "" {
+		msg = fmt.Sprintf("%s %s", msg, e.Type)
+	}
+	if e.Reason != "" {
+		msg = fmt.Sprintf("%s: %s", msg, e.Reason)
+	}
+	return msg
+}
+
+// Is reports whether the error matches one of the sentinel errors of this package
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrDatabaseNotFound:
+		return e.StatusCode == http.StatusNotFound && (e.Reason == "Database does not exist." || e.Reason == "no_db_file")
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrPreconditionFailed:
+		return e.StatusCode == http.StatusPreconditionFailed
+	case ErrTooLarge:
+		return e.StatusCode == http.StatusRequestEntityTooLarge
+	}
+	return false
+}
+
+// As allows retrieving the plain couchdb error body using errors.As
+func (e *Error) As(target interface{}) bool {
+	if res, ok := target.(*ErrorResponse); ok {
+		*res = e.ErrorResponse
+		return true
+	}
+	return false
+}
+
+// newError builds an *Error for a response to req with an unexpected status.
+// body holds the response body, if it has been read.
+func newError(req *http.Request, resp *http.Response, body []byte) *Error {
+	e := &Error{
+		StatusCode: resp.StatusCode,
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		RequestID:  resp.Header.Get("X-Couch-Request-ID"),
+	}
+	if len(body) > 0 {
+		json.Unmarshal(body, &e.ErrorResponse)
+	}
+	return e
+}
@@ -0,0 +1,51 @@
+// +build !integration
+
+package couchdb
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestError_Is(t *testing.T) {
+	t.Parallel()
+
+	t.Run("database not found", func(t *testing.T) {
+		var doc Document
+		err := client.Database("missing-db").Get(context.Background(), "test", &doc)
+		if !errors.Is(err, ErrDatabaseNotFound) {
+			t.Fatalf("Expected ErrDatabaseNotFound, got %v", err)
+		}
+		if !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("document not found", func(t *testing.T) {
+		if _, err := playground.Rev(context.Background(), "employee:unknown"); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		_, err := playground.Put(context.Background(), "pet:yumi", testDoc{Name: "Yumi"})
+		if !errors.Is(err, ErrConflict) {
+			t.Fatalf("Expected ErrConflict, got %v", err)
+		}
+		var apiErr *Error
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("Expected *Error, got %T", err)
+		}
+		if apiErr.Type != "conflict" {
+			t.Fatalf("Expected conflict error type, got %q", apiErr.Type)
+		}
+	})
+
+	t.Run("precondition failed", func(t *testing.T) {
+		err := client.Databases.Create("playground", DatabaseClusterOptions{})
+		if !errors.Is(err, ErrPreconditionFailed) {
+			t.Fatalf("Expected ErrPreconditionFailed, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,370 @@
+package couchdb
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Feed types supported by couchdb's changes-like endpoints
+const (
+	// FeedNormal returns all changes in a single response
+	FeedNormal = "normal"
+	// FeedLongpoll waits for at least one change before responding
+	FeedLongpoll = "longpoll"
+	// FeedContinuous keeps the connection open, streaming changes as they happen
+	FeedContinuous = "continuous"
+	// FeedEventSource streams changes as server sent events
+	FeedEventSource = "eventsource"
+)
+
+// SinceNow makes a feed start with changes happening after the request
+const SinceNow Sequence = "now"
+
+// Sequence identifies a position in a feed. couchdb 1.x uses numbers, 2.x opaque strings.
+type Sequence string
+
+// UnmarshalJSON accepts both numeric and string sequences
+func (s *Sequence) UnmarshalJSON(bs []byte) error {
+	if bytes.Equal(bs, []byte("null")) {
+		*s = ""
+		return nil
+	}
+	if len(bs) > 0 && bs[0] == '"' {
+		var str string
+		if err := json.Unmarshal(bs, &str); err != nil {
+			return err
+		}
+		*s = Sequence(str)
+		return nil
+	}
+	*s = Sequence(bs)
+	return nil
+}
+
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = time.Minute
+)
+
+// feed decodes the events of changes-like endpoints for all feed types. Following feeds, i.e.
+// continuous and eventsource ones, are reopened whenever the server ends a response. Network
+// errors cause the feed to reconnect, resuming after the last event seen.
+type feed struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	open   func(ctx context.Context, since Sequence) (*http.Response, error)
+	mode   string
+	follow bool
+	since  Sequence
+
+	mu     sync.Mutex
+	body   io.ReadCloser
+	closed bool
+
+	connected bool
+	dec       *json.Decoder
+	lines     *bufio.Reader
+	inResults bool
+
+	event   json.RawMessage
+	lastSeq Sequence
+	pending int
+	delay   time.Duration
+	err     error
+	done    bool
+}
+
+func newFeed(ctx context.Context, mode string, follow bool, since Sequence, open func(context.Context, Sequence) (*http.Response, error)) (*feed, error) {
+	if mode == "" {
+		mode = FeedNormal
+	}
+	switch mode {
+	case FeedNormal, FeedLongpoll, FeedContinuous, FeedEventSource:
+	default:
+		return nil, errors.New("couchdb: unknown feed type " + mode)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	f := &feed{
+		ctx:    ctx,
+		cancel: cancel,
+		open:   open,
+		mode:   mode,
+		follow: follow,
+		since:  since,
+	}
+	// the initial request fails fast, e.g. for missing databases
+	resp, err := open(ctx, since)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	f.attach(resp.Body)
+	return f, nil
+}
+
+func (f *feed) attach(body io.ReadCloser) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		// reading fails right away, ending the feed
+		body.Close()
+	}
+	f.body = body
+	f.connected = true
+	f.inResults = false
+	if f.mode == FeedEventSource {
+		f.lines = bufio.NewReader(body)
+	} else {
+		f.dec = json.NewDecoder(body)
+	}
+}
+
+// detach closes the current response body. It is safe to call while the body is being read.
+func (f *feed) detach() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.body != nil {
+		f.body.Close()
+		f.body = nil
+	}
+}
+
+// next advances to the next event, returning false once the feed is exhausted or failed
+func (f *feed) next() bool {
+	for {
+		if f.done || f.err != nil {
+			return false
+		}
+		if !f.connected {
+			if err := f.reconnect(); err != nil {
+				f.fail(err)
+				return false
+			}
+		}
+
+		event, end, err := f.read()
+		if err == nil && !end {
+			f.event = event
+			f.delay = 0
+			return true
+		}
+		f.connected = false
+		f.detach()
+		if f.ctx.Err() != nil {
+			f.fail(f.ctx.Err())
+			return false
+		}
+		if err == nil && !f.follow {
+			f.done = true
+			return false
+		}
+		if err != nil {
+			if err := f.backoff(); err != nil {
+				f.fail(err)
+				return false
+			}
+		}
+	}
+}
+
+// fail stops the feed. Errors caused by closing the feed are not reported.
+func (f *feed) fail(err error) {
+	f.mu.Lock()
+	closed := f.closed
+	f.mu.Unlock()
+	if !closed {
+		f.err = err
+	}
+	f.done = true
+}
+
+// reconnect reopens the feed after the last event seen, retrying while couchdb is unreachable
+func (f *feed) reconnect() error {
+	since := f.since
+	if f.lastSeq != "" {
+		since = f.lastSeq
+	}
+	for {
+		resp, err := f.open(f.ctx, since)
+		if err == nil {
+			f.attach(resp.Body)
+			return nil
+		}
+		var apiErr *Error
+		if errors.As(err, &apiErr) && apiErr.StatusCode < http.StatusInternalServerError {
+			return err
+		}
+		if f.ctx.Err() != nil {
+			return f.ctx.Err()
+		}
+		if err := f.backoff(); err != nil {
+			return err
+		}
+	}
+}
+
+// backoff waits before reconnecting, growing the delay while errors keep occurring
+func (f *feed) backoff() error {
+	f.delay *= 2
+	if f.delay < minReconnectDelay {
+		f.delay = minReconnectDelay
+	}
+	if f.delay > maxReconnectDelay {
+		f.delay = maxReconnectDelay
+	}
+	return sleep(f.ctx, f.delay)
+}
+
+type feedPosition struct {
+	Seq     *Sequence `json:"seq"`
+	LastSeq *Sequence `json:"last_seq"`
+	Pending *int      `json:"pending"`
+}
+
+// track records the position reported by an event, reporting whether it marks the end of a response
+func (f *feed) track(event json.RawMessage) (bool, error) {
+	pos := feedPosition{}
+	if err := json.Unmarshal(event, &pos); err != nil {
+		return false, err
+	}
+	if pos.Pending != nil {
+		f.pending = *pos.Pending
+	}
+	if pos.Seq != nil {
+		f.lastSeq = *pos.Seq
+		return false, nil
+	}
+	if pos.LastSeq != nil {
+		f.lastSeq = *pos.LastSeq
+		return true, nil
+	}
+	return false, nil
+}
+
+// read returns the next event of the current response, or end once the response is complete
+func (f *feed) read() (event json.RawMessage, end bool, err error) {
+	switch f.mode {
+	case FeedContinuous:
+		return f.readContinuous()
+	case FeedEventSource:
+		return f.readEventSource()
+	}
+	return f.readResults()
+}
+
+// readResults decodes {"results":[…],"last_seq":…} responses one result at a time
+func (f *feed) readResults() (json.RawMessage, bool, error) {
+	for {
+		if f.inResults {
+			if f.dec.More() {
+				var event json.RawMessage
+				if err := f.dec.Decode(&event); err != nil {
+					return nil, false, err
+				}
+				_, err := f.track(event)
+				return event, false, err
+			}
+			if _, err := f.dec.Token(); err != nil {
+				return nil, false, err
+			}
+			f.inResults = false
+			continue
+		}
+
+		token, err := f.dec.Token()
+		if err != nil {
+			return nil, false, err
+		}
+		switch token {
+		case json.Delim('{'):
+		case json.Delim('}'):
+			return nil, true, nil
+		case "results":
+			if _, err := f.dec.Token(); err != nil {
+				return nil, false, err
+			}
+			f.inResults = true
+		case "last_seq":
+			if err := f.dec.Decode(&f.lastSeq); err != nil {
+				return nil, false, err
+			}
+		case "pending":
+			if err := f.dec.Decode(&f.pending); err != nil {
+				return nil, false, err
+			}
+		default:
+			var skip json.RawMessage
+			if err := f.dec.Decode(&skip); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+}
+
+// readContinuous decodes newline delimited events, skipping heartbeats
+func (f *feed) readContinuous() (json.RawMessage, bool, error) {
+	var event json.RawMessage
+	if err := f.dec.Decode(&event); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, false, err
+	}
+	end, err := f.track(event)
+	if end {
+		return nil, true, err
+	}
+	return event, false, err
+}
+
+// readEventSource decodes server sent events, skipping heartbeats
+func (f *feed) readEventSource() (json.RawMessage, bool, error) {
+	data := []byte{}
+	for {
+		line, err := f.lines.ReadBytes('\n')
+		if err == io.EOF && len(line) == 0 {
+			// couchdb ends eventsource responses by closing the connection
+			return nil, true, nil
+		}
+		if err != nil && err != io.EOF {
+			return nil, false, err
+		}
+		line = bytes.TrimRight(line, "\r\n")
+		if len(line) > 0 {
+			if bytes.HasPrefix(line, []byte("data:")) {
+				data = append(data, bytes.TrimSpace(line[len("data:"):])...)
+			}
+			continue
+		}
+		if len(data) == 0 {
+			continue
+		}
+		event := json.RawMessage(data)
+		end, err := f.track(event)
+		if err != nil {
+			return nil, false, err
+		}
+		if end {
+			data = []byte{}
+			continue
+		}
+		return event, false, nil
+	}
+}
+
+// close stops the feed, aborting any pending request
+func (f *feed) close() error {
+	f.mu.Lock()
+	f.closed = true
+	f.mu.Unlock()
+	f.cancel()
+	f.detach()
+	return nil
+}
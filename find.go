@@ -0,0 +1,118 @@
+package couchdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+)
+
+// SortField orders Mango query results by a single field
+type SortField struct {
+	Field      string
+	Descending bool
+}
+
+// MarshalJSON encodes the sort field as {"field": "asc"} or {"field": "desc"}
+func (s SortField) MarshalJSON() ([]byte, error) {
+	direction := "asc"
+	if s.Descending {
+		direction = "desc"
+	}
+	return json.Marshal(map[string]string{s.Field: direction})
+}
+
+// Asc sorts results by field in ascending order
+func Asc(field string) SortField {
+	return SortField{Field: field}
+}
+
+// Desc sorts results by field in descending order
+func Desc(field string) SortField {
+	return SortField{Field: field, Descending: true}
+}
+
+// FindQuery describes a Mango query
+type FindQuery struct {
+	// Selector is usually built with the selector functions, e.g. Eq or And
+	Selector interface{} `json:"selector"`
+	Fields   []string    `json:"fields,omitempty"`
+	Sort     []SortField `json:"sort,omitempty"`
+	Limit    int         `json:"limit,omitempty"`
+	Skip     int         `json:"skip,omitempty"`
+	// Bookmark continues a previous query, see FindResults.Bookmark
+	Bookmark string `json:"bookmark,omitempty"`
+	// UseIndex is either a design document name or a [design document, index name] pair
+	UseIndex       interface{} `json:"use_index,omitempty"`
+	R              int         `json:"r,omitempty"`
+	Conflicts      bool        `json:"conflicts,omitempty"`
+	ExecutionStats bool        `json:"execution_stats,omitempty"`
+	// Update set to false uses an existing index without updating it first
+	Update *bool `json:"update,omitempty"`
+	Stable bool  `json:"stable,omitempty"`
+}
+
+// ExecutionStats describes the work done by couchdb to answer a Mango query
+type ExecutionStats struct {
+	TotalKeysExamined       int     `json:"total_keys_examined"`
+	TotalDocsExamined       int     `json:"total_docs_examined"`
+	TotalQuorumDocsExamined int     `json:"total_quorum_docs_examined"`
+	ResultsReturned         int     `json:"results_returned"`
+	ExecutionTimeMs         float64 `json:"execution_time_ms"`
+}
+
+// FindResults contains the metadata of a Mango query response
+type FindResults struct {
+	Bookmark string `json:"bookmark"`
+	// Warning is set if couchdb considers the query inefficient, e.g. because no index matched
+	Warning        string          `json:"warning"`
+	ExecutionStats *ExecutionStats `json:"execution_stats"`
+}
+
+// Find executes a Mango query. POST /{db}/_find
+// The response is decoded into results, which should contain the matching documents, e.g.
+//
+//   type employees struct {
+//     Docs []Employee `json:"docs"`
+//   }
+//   var results employees
+//   meta, err := db.Find(ctx, couchdb.FindQuery{
+//     Selector: couchdb.Eq("type", "employee"),
+//     Sort:     []couchdb.SortField{couchdb.Asc("name")},
+//   }, &results)
+func (d *Database) Find(ctx context.Context, query FindQuery, results interface{}) (*FindResults, error) {
+	if query.Selector == nil {
+		query.Selector = Selector{}
+	}
+	bs, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", "/_find", bytes.NewReader(bs))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	resp, err := d.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newError(req, resp, nil)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	meta := FindResults{}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, results); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
@@ -0,0 +1,54 @@
+// +build !integration
+
+package couchdb
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestSelector_MarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	bs, err := json.Marshal(And(
+		Eq("type", "employee"),
+		Or(Gte("age", 18), Exists("guardian", true)),
+		ElemMatch("tags", Regex("", "^sales")),
+		AllMatch("scores", Gte("", 0)),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"$and":[{"type":{"$eq":"employee"}},{"$or":[{"age":{"$gte":18}},{"guardian":{"$exists":true}}]},{"tags":{"$elemMatch":{"$regex":"^sales"}}},{"scores":{"$allMatch":{"$gte":0}}}]}`
+	if string(bs) != expected {
+		t.Fatalf("Expected %s, got %s", expected, string(bs))
+	}
+}
+
+func TestDatabase_Find(t *testing.T) {
+	t.Parallel()
+	if !client.CouchDB.HasClusterSupport() {
+		t.Skip("_find requires couchdb 2.x")
+	}
+
+	var results struct {
+		Docs []testDoc `json:"docs"`
+	}
+	meta, err := playground.Find(context.Background(), FindQuery{
+		Selector:       In("name", "Michael", "Yumi"),
+		ExecutionStats: true,
+	}, &results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results.Docs) != 2 {
+		t.Fatalf("Expected 2 docs, got %d", len(results.Docs))
+	}
+	if meta.Warning == "" {
+		t.Fatal("Expected a warning about the missing index")
+	}
+	if meta.ExecutionStats == nil || meta.ExecutionStats.ResultsReturned != 2 {
+		t.Fatal("Expected execution stats to be returned")
+	}
+}
@@ -0,0 +1,406 @@
+package couchdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Types of Mango indexes
+const (
+	IndexTypeJSON = "json"
+	IndexTypeText = "text"
+	// IndexTypeSpecial is used by the built-in _all_docs index
+	IndexTypeSpecial = "special"
+)
+
+// IndexField is a single field covered by a Mango index. Type is the sort direction of json
+// indexes, "asc" or "desc", or the field type of text indexes, e.g. "string".
+type IndexField struct {
+	Name string
+	Type string
+}
+
+// MarshalJSON encodes the field as {"name": "type"}, or "name" if no type is set
+func (f IndexField) MarshalJSON() ([]byte, error) {
+	if f.Type == "" {
+		return json.Marshal(f.Name)
+	}
+	return json.Marshal(map[string]string{f.Name: f.Type})
+}
+
+// UnmarshalJSON accepts both "name" and {"name": "type"}
+func (f *IndexField) UnmarshalJSON(bs []byte) error {
+	var name string
+	if err := json.Unmarshal(bs, &name); err == nil {
+		*f = IndexField{Name: name}
+		return nil
+	}
+	field := map[string]string{}
+	if err := json.Unmarshal(bs, &field); err != nil {
+		return err
+	}
+	if len(field) != 1 {
+		return fmt.Errorf("couchdb: invalid index field %s", string(bs))
+	}
+	for name, typ := range field {
+		*f = IndexField{Name: name, Type: typ}
+	}
+	return nil
+}
+
+// IndexDefinition describes what a Mango index covers
+type IndexDefinition struct {
+	Fields []IndexField `json:"fields"`
+	// PartialFilterSelector restricts the index to matching documents
+	PartialFilterSelector interface{} `json:"partial_filter_selector,omitempty"`
+	// DefaultField configures the default field of text indexes
+	DefaultField interface{} `json:"default_field,omitempty"`
+	// Selector restricts text indexes to matching documents
+	Selector interface{} `json:"selector,omitempty"`
+}
+
+// Index describes a Mango index
+type Index struct {
+	// DDoc is the design document holding the index, with or without _design/ prefix
+	DDoc string `json:"ddoc,omitempty"`
+	Name string `json:"name,omitempty"`
+	// Type defaults to IndexTypeJSON
+	Type        string          `json:"type,omitempty"`
+	Def         IndexDefinition `json:"def"`
+	Partitioned *bool           `json:"partitioned,omitempty"`
+}
+
+type createIndexRequest struct {
+	Index       interface{} `json:"index"`
+	DDoc        string      `json:"ddoc,omitempty"`
+	Name        string      `json:"name,omitempty"`
+	Type        string      `json:"type,omitempty"`
+	Partitioned *bool       `json:"partitioned,omitempty"`
+}
+
+// IndexResult is the outcome of creating an index
+type IndexResult struct {
+	// Result is either "created" or "exists"
+	Result string `json:"result"`
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+}
+
+// textIndexField is an IndexField as text indexes expect it on creation, {"name": "name", "type": "type"}
+type textIndexField IndexField
+
+func (f textIndexField) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"name": f.Name, "type": f.Type})
+}
+
+type textIndexDefinition struct {
+	IndexDefinition
+	Fields []textIndexField `json:"fields"`
+}
+
+// CreateIndex creates a Mango index. POST /{db}/_index
+func (d *Database) CreateIndex(ctx context.Context, idx Index) (*IndexResult, error) {
+	var def interface{} = idx.Def
+	if idx.Type == IndexTypeText {
+		fields := make([]textIndexField, len(idx.Def.Fields))
+		for i, field := range idx.Def.Fields {
+			fields[i] = textIndexField(field)
+		}
+		def = textIndexDefinition{IndexDefinition: idx.Def, Fields: fields}
+	}
+	bs, err := json.Marshal(createIndexRequest{
+		Index:       def,
+		DDoc:        strings.TrimPrefix(idx.DDoc, "_design/"),
+		Name:        idx.Name,
+		Type:        idx.Type,
+		Partitioned: idx.Partitioned,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", "/_index", bytes.NewReader(bs))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	resp, err := d.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newError(req, resp, nil)
+	}
+	result := IndexResult{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+type indexList struct {
+	TotalRows int     `json:"total_rows"`
+	Indexes   []Index `json:"indexes"`
+}
+
+// ListIndexes fetches all Mango indexes of the database, including the special _all_docs index.
+// GET /{db}/_index
+func (d *Database) ListIndexes(ctx context.Context) ([]Index, error) {
+	req, err := http.NewRequest("GET", "/_index", nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	resp, err := d.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newError(req, resp, nil)
+	}
+	list := indexList{}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, err
+	}
+	return list.Indexes, nil
+}
+
+// DeleteIndex removes a Mango index. DELETE /{db}/_index/{ddoc}/{type}/{name}
+func (d *Database) DeleteIndex(ctx context.Context, idx Index) error {
+	typ := idx.Type
+	if typ == "" {
+		typ = IndexTypeJSON
+	}
+	path := fmt.Sprintf("/_index/%s/%s/%s", strings.TrimPrefix(idx.DDoc, "_design/"), typ, idx.Name)
+	req, err := http.NewRequest("DELETE", path, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	resp, err := d.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return newError(req, resp, nil)
+	}
+	return nil
+}
+
+// EnsureIndex makes sure the index exists exactly as given, which makes it safe to call on
+// every deployment. An index with the same design document and name but a different definition
+// is replaced. It reports whether the index had to be created.
+func (d *Database) EnsureIndex(ctx context.Context, idx Index) (bool, error) {
+	if idx.DDoc == "" || idx.Name == "" {
+		return false, errors.New("couchdb: EnsureIndex requires a design document and a name")
+	}
+	indexes, err := d.ListIndexes(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, existing := range indexes {
+		if strings.TrimPrefix(existing.DDoc, "_design/") != strings.TrimPrefix(idx.DDoc, "_design/") || existing.Name != idx.Name {
+			continue
+		}
+		same, err := sameIndex(existing, idx)
+		if err != nil {
+			return false, err
+		}
+		if same {
+			return false, nil
+		}
+		if err := d.DeleteIndex(ctx, existing); err != nil {
+			return false, err
+		}
+	}
+	if _, err := d.CreateIndex(ctx, idx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// sameIndex compares index definitions in the normalized form couchdb lists them in: fields of
+// json indexes default to ascending order, omitted options of text indexes are listed as {} and
+// selectors are normalized, e.g. {"type": "employee"} becomes {"type": {"$eq": "employee"}}
+func sameIndex(existing, idx Index) (bool, error) {
+	typ := idx.Type
+	if typ == "" {
+		typ = IndexTypeJSON
+	}
+	if existing.Type != typ {
+		return false, nil
+	}
+	a, err := normalizeIndexDefinition(existing.Def, typ)
+	if err != nil {
+		return false, err
+	}
+	b, err := normalizeIndexDefinition(idx.Def, typ)
+	if err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(a, b), nil
+}
+
+func normalizeIndexDefinition(def IndexDefinition, typ string) (interface{}, error) {
+	fields := make([]IndexField, len(def.Fields))
+	for i, field := range def.Fields {
+		if field.Type == "" && typ == IndexTypeJSON {
+			field.Type = "asc"
+		}
+		fields[i] = field
+	}
+	def.Fields = fields
+	v, err := normalizeJSON(def)
+	if err != nil {
+		return nil, err
+	}
+	normalized := v.(map[string]interface{})
+	for _, key := range []string{"partial_filter_selector", "selector", "default_field"} {
+		value, ok := normalized[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if key != "default_field" {
+			value = normalizeSelector(value)
+		}
+		if len(value) == 0 {
+			delete(normalized, key)
+			continue
+		}
+		normalized[key] = value
+	}
+	return normalized, nil
+}
+
+// normalizeSelector rewrites a decoded selector the way couchdb normalizes stored selectors:
+// multiple conditions become an explicit $and, literals become $eq conditions and nested fields
+// become dotted paths. The arguments of $and, $or and $nor are sorted, as their order does not
+// change the selector's meaning but is lost when decoding objects.
+func normalizeSelector(s map[string]interface{}) map[string]interface{} {
+	if len(s) > 1 {
+		conditions := []interface{}{}
+		for key, value := range s {
+			conditions = append(conditions, map[string]interface{}{key: value})
+		}
+		return normalizeSelector(map[string]interface{}{"$and": conditions})
+	}
+	for key, value := range s {
+		switch key {
+		case "$and", "$or", "$nor":
+			args, ok := value.([]interface{})
+			if !ok {
+				return s
+			}
+			normalized := make([]interface{}, len(args))
+			for i, arg := range args {
+				normalized[i] = arg
+				if sub, ok := arg.(map[string]interface{}); ok {
+					normalized[i] = normalizeSelector(sub)
+				}
+			}
+			sort.Slice(normalized, func(i, j int) bool {
+				a, _ := json.Marshal(normalized[i])
+				b, _ := json.Marshal(normalized[j])
+				return string(a) < string(b)
+			})
+			return map[string]interface{}{key: normalized}
+		case "$not", "$elemMatch", "$allMatch":
+			if sub, ok := value.(map[string]interface{}); ok {
+				return map[string]interface{}{key: normalizeSelector(sub)}
+			}
+			return s
+		}
+		if strings.HasPrefix(key, "$") {
+			return s
+		}
+		return normalizeField(key, value)
+	}
+	return s
+}
+
+// normalizeField normalizes the condition on a single field
+func normalizeField(field string, value interface{}) map[string]interface{} {
+	cond, ok := value.(map[string]interface{})
+	if !ok || len(cond) == 0 {
+		return map[string]interface{}{field: map[string]interface{}{"$eq": value}}
+	}
+	if len(cond) > 1 {
+		conditions := []interface{}{}
+		for key, value := range cond {
+			conditions = append(conditions, map[string]interface{}{field: map[string]interface{}{key: value}})
+		}
+		return normalizeSelector(map[string]interface{}{"$and": conditions})
+	}
+	for key, value := range cond {
+		if strings.HasPrefix(key, "$") {
+			return map[string]interface{}{field: normalizeSelector(cond)}
+		}
+		return normalizeField(field+"."+key, value)
+	}
+	return nil
+}
+
+func normalizeJSON(v interface{}) (interface{}, error) {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	err = json.Unmarshal(bs, &normalized)
+	return normalized, err
+}
+
+// Explanation describes how couchdb answers a Mango query
+type Explanation struct {
+	DBName   string                 `json:"dbname"`
+	Index    Index                  `json:"index"`
+	Selector json.RawMessage        `json:"selector"`
+	Opts     map[string]interface{} `json:"opts"`
+	Limit    int                    `json:"limit"`
+	Skip     int                    `json:"skip"`
+	Fields   interface{}            `json:"fields"`
+	Range    json.RawMessage        `json:"range,omitempty"`
+}
+
+// UsesIndex reports whether the query is served by an index instead of scanning all documents
+func (e Explanation) UsesIndex() bool {
+	return e.Index.Type != IndexTypeSpecial
+}
+
+// Explain describes which index couchdb would use to answer the query. POST /{db}/_explain
+func (d *Database) Explain(ctx context.Context, query FindQuery) (*Explanation, error) {
+	if query.Selector == nil {
+		query.Selector = Selector{}
+	}
+	bs, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", "/_explain", bytes.NewReader(bs))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	resp, err := d.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newError(req, resp, nil)
+	}
+	explanation := Explanation{}
+	if err := json.NewDecoder(resp.Body).Decode(&explanation); err != nil {
+		return nil, err
+	}
+	return &explanation, nil
+}
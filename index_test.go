@@ -0,0 +1,141 @@
+// +build !integration
+
+package couchdb
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDatabase_Indexes(t *testing.T) {
+	t.Parallel()
+	if !client.CouchDB.HasClusterSupport() {
+		t.Skip("_index requires couchdb 2.x")
+	}
+
+	db := client.Database("index-test")
+	client.Databases.Create(db.Name, DatabaseClusterOptions{})
+	defer client.Databases.Delete(db.Name)
+
+	idx := Index{
+		DDoc: "employees",
+		Name: "by-name",
+		Def: IndexDefinition{
+			Fields:                []IndexField{{Name: "name"}},
+			PartialFilterSelector: Eq("type", "employee"),
+		},
+	}
+
+	t.Run("ensure", func(t *testing.T) {
+		created, err := db.EnsureIndex(context.Background(), idx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !created {
+			t.Fatal("Expected index to be created")
+		}
+		created, err = db.EnsureIndex(context.Background(), idx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created {
+			t.Fatal("Expected existing index to be kept")
+		}
+	})
+
+	t.Run("explain", func(t *testing.T) {
+		explanation, err := db.Explain(context.Background(), FindQuery{
+			Selector: And(Eq("type", "employee"), Gt("name", nil)),
+			UseIndex: []string{"employees", "by-name"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !explanation.UsesIndex() || explanation.Index.Name != "by-name" {
+			t.Fatalf("Expected query to use index %q, got %q", "by-name", explanation.Index.Name)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := db.DeleteIndex(context.Background(), idx); err != nil {
+			t.Fatal(err)
+		}
+		indexes, err := db.ListIndexes(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, existing := range indexes {
+			if existing.Name == idx.Name {
+				t.Fatal("Expected index to be deleted")
+			}
+		}
+	})
+}
+
+func TestDatabase_EnsureIndex_normalized(t *testing.T) {
+	t.Parallel()
+
+	// indexes as listed by couchdb, with defaults applied and selectors normalized
+	listing := `{"total_rows":3,"indexes":[
+		{"ddoc":null,"name":"_all_docs","type":"special","def":{"fields":[{"_id":"asc"}]}},
+		{"ddoc":"_design/employees","name":"by-name","type":"json","partitioned":false,"def":{"fields":[{"name":"asc"}],"partial_filter_selector":{"$and":[{"type":{"$eq":"employee"}},{"address.city":{"$eq":"Berlin"}}]}}},
+		{"ddoc":"_design/search","name":"by-text","type":"text","partitioned":false,"def":{"default_analyzer":"keyword","default_field":{},"selector":{},"fields":[{"name":"string"},{"age":"number"}],"index_array_lengths":true}}
+	]}`
+	var created []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Write([]byte(listing))
+		case "DELETE":
+			w.Write([]byte(`{"ok":true}`))
+		case "POST":
+			bs, _ := ioutil.ReadAll(r.Body)
+			created = append(created, string(bs))
+			w.Write([]byte(`{"result":"created","id":"_design/search","name":"by-text"}`))
+		}
+	}))
+	defer srv.Close()
+	db := (&Client{Host: srv.URL, client: &http.Client{}}).Database("index-test")
+
+	text := Index{
+		DDoc: "search",
+		Name: "by-text",
+		Type: IndexTypeText,
+		Def:  IndexDefinition{Fields: []IndexField{{Name: "name", Type: "string"}, {Name: "age", Type: "number"}}},
+	}
+	for _, idx := range []Index{
+		text,
+		{
+			DDoc: "employees",
+			Name: "by-name",
+			Def: IndexDefinition{
+				Fields: []IndexField{{Name: "name"}},
+				PartialFilterSelector: Selector{
+					"type":    "employee",
+					"address": map[string]interface{}{"city": "Berlin"},
+				},
+			},
+		},
+	} {
+		ok, err := db.EnsureIndex(context.Background(), idx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok {
+			t.Fatalf("Expected existing index %q to be kept", idx.Name)
+		}
+	}
+
+	text.Def.Selector = Eq("type", "employee")
+	ok, err := db.EnsureIndex(context.Background(), text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"index":{"selector":{"type":{"$eq":"employee"}},"fields":[{"name":"name","type":"string"},{"name":"age","type":"number"}]},"ddoc":"search","name":"by-text","type":"text"}`
+	if !ok || len(created) != 1 || created[0] != expected {
+		t.Fatalf("Expected changed index to be created as %s, got %v", expected, created)
+	}
+}
@@ -0,0 +1,304 @@
+package couchdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"sort"
+)
+
+// MultipartAttachment is an attachment written together with its document using PutMultipart
+type MultipartAttachment struct {
+	Name        string
+	ContentType string
+	// Length must match the number of bytes read from Body
+	Length int64
+	Body   io.Reader
+}
+
+type followsStub struct {
+	Follows     bool   `json:"follows"`
+	ContentType string `json:"content_type"`
+	Length      int64  `json:"length"`
+}
+
+// multipartBody assembles the JSON body of a multipart/related document write, announcing
+// that the given attachments follow as separate parts. couchdb assigns parts to stubs in the
+// order the stubs appear in the JSON, which encoding/json sorts by name, so the attachments
+// are returned sorted by name as well; their parts must be written in that order.
+func multipartBody(doc interface{}, attachments []MultipartAttachment) ([]byte, []MultipartAttachment, error) {
+	bs, err := json.Marshal(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(bs, &fields); err != nil {
+		return nil, nil, fmt.Errorf("couchdb: multipart documents must be JSON objects: %v", err)
+	}
+	stubs := map[string]json.RawMessage{}
+	if raw, ok := fields["_attachments"]; ok {
+		if err := json.Unmarshal(raw, &stubs); err != nil {
+			return nil, nil, err
+		}
+	}
+	sorted := map[string]MultipartAttachment{}
+	for _, att := range attachments {
+		if _, ok := sorted[att.Name]; ok {
+			return nil, nil, fmt.Errorf("couchdb: attachment %q given more than once", att.Name)
+		}
+		sorted[att.Name] = att
+		stub, err := json.Marshal(followsStub{
+			Follows:     true,
+			ContentType: att.ContentType,
+			Length:      att.Length,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		stubs[att.Name] = stub
+	}
+	if fields["_attachments"], err = json.Marshal(stubs); err != nil {
+		return nil, nil, err
+	}
+	if bs, err = json.Marshal(fields); err != nil {
+		return nil, nil, err
+	}
+
+	names := make([]string, 0, len(sorted))
+	for name := range sorted {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	ordered := make([]MultipartAttachment, len(names))
+	for i, name := range names {
+		ordered[i] = sorted[name]
+	}
+	return bs, ordered, nil
+}
+
+// writeMultipart writes the document and its attachments as multipart/related parts to w.
+// If withBodies is false, attachment bodies are skipped to measure the framing overhead.
+func writeMultipart(mw *multipart.Writer, body []byte, attachments []MultipartAttachment, withBodies bool) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", "application/json")
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(body); err != nil {
+		return err
+	}
+	for _, att := range attachments {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", att.ContentType)
+		part, err := mw.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		if !withBodies {
+			continue
+		}
+		if n, err := io.Copy(part, att.Body); err != nil {
+			return err
+		} else if n != att.Length {
+			return fmt.Errorf("couchdb: attachment %q is %d bytes long, announced %d", att.Name, n, att.Length)
+		}
+	}
+	return mw.Close()
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// PutMultipart creates or updates a document together with attachments using a single request.
+// PUT /{db}/{docid} with Content-Type multipart/related
+// Attachment bodies are streamed to couchdb; attachment stubs already present on doc are kept.
+func (d *Database) PutMultipart(ctx context.Context, id string, doc interface{}, attachments []MultipartAttachment) (string, error) {
+	body, attachments, err := multipartBody(doc, attachments)
+	if err != nil {
+		return "", err
+	}
+
+	// couchdb needs to know the request size upfront, so measure the framing first
+	counter := &countingWriter{}
+	mw := multipart.NewWriter(counter)
+	if err := writeMultipart(mw, body, attachments, false); err != nil {
+		return "", err
+	}
+	length := counter.n
+	for _, att := range attachments {
+		length += att.Length
+	}
+
+	pr, pw := io.Pipe()
+	stream := multipart.NewWriter(pw)
+	if err := stream.SetBoundary(mw.Boundary()); err != nil {
+		return "", err
+	}
+	go func() {
+		pw.CloseWithError(writeMultipart(stream, body, attachments, true))
+	}()
+	defer pr.Close()
+
+	req, err := http.NewRequest("PUT", fmt.Sprintf("/%s", id), pr)
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+	req.ContentLength = length
+	req.Header.Set("Content-Type", fmt.Sprintf("multipart/related; boundary=%q", stream.Boundary()))
+
+	resp, err := d.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusAccepted {
+		return "", newError(req, resp, nil)
+	}
+	res := revResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return "", err
+	}
+	return res.Rev, nil
+}
+
+// MultipartDocument streams a document and all its attachments fetched with GetMultipart.
+// The JSON body has to be consumed before the attachments. It must be closed by the caller.
+type MultipartDocument struct {
+	body   io.ReadCloser
+	parts  *multipart.Reader
+	doc    io.Reader
+	opened bool
+}
+
+// AttachmentPart is a single attachment of a MultipartDocument
+type AttachmentPart struct {
+	io.Reader
+	Name        string
+	ContentType string
+}
+
+// GetMultipart fetches a document together with all its attachments using a single request.
+// GET /{db}/{docid}?attachments=true with Accept multipart/related
+//
+//   mp, err := db.GetMultipart(ctx, "invoice:1")
+//   if err != nil {
+//     return err
+//   }
+//   defer mp.Close()
+//   var invoice Invoice
+//   if err := mp.Decode(&invoice); err != nil {
+//     return err
+//   }
+//   for {
+//     att, err := mp.NextAttachment()
+//     if err == io.EOF {
+//       break
+//     }
+//     // … read att
+//   }
+func (d *Database) GetMultipart(ctx context.Context, id string) (*MultipartDocument, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("/%s", id), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	values := req.URL.Query()
+	values.Set("attachments", "true")
+	req.URL.RawQuery = values.Encode()
+	req.Header.Set("Accept", "multipart/related")
+
+	resp, err := d.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, newError(req, resp, nil)
+	}
+
+	mp := &MultipartDocument{body: resp.Body}
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	// documents without attachments are returned as plain JSON
+	if mediaType == "multipart/related" {
+		mp.parts = multipart.NewReader(resp.Body, params["boundary"])
+	} else {
+		mp.doc = resp.Body
+	}
+	return mp, nil
+}
+
+// JSON returns a reader for the JSON body of the document
+func (m *MultipartDocument) JSON() (io.Reader, error) {
+	if m.opened {
+		return nil, errors.New("couchdb: multipart document body has already been read")
+	}
+	m.opened = true
+	if m.parts == nil {
+		return m.doc, nil
+	}
+	part, err := m.parts.NextPart()
+	if err != nil {
+		return nil, err
+	}
+	return part, nil
+}
+
+// Decode decodes the JSON body of the document into doc
+func (m *MultipartDocument) Decode(doc interface{}) error {
+	r, err := m.JSON()
+	if err != nil {
+		return err
+	}
+	return json.NewDecoder(r).Decode(doc)
+}
+
+// NextAttachment returns the next attachment, or io.EOF if there are no more attachments.
+// The previous attachment can no longer be read afterwards.
+func (m *MultipartDocument) NextAttachment() (*AttachmentPart, error) {
+	if !m.opened {
+		if _, err := m.JSON(); err != nil {
+			return nil, err
+		}
+	}
+	if m.parts == nil {
+		return nil, io.EOF
+	}
+	part, err := m.parts.NextPart()
+	if err != nil {
+		return nil, err
+	}
+	// part.FileName strips directories, but attachment names may contain slashes
+	_, params, err := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+	if err != nil {
+		return nil, err
+	}
+	return &AttachmentPart{
+		Reader:      part,
+		Name:        params["filename"],
+		ContentType: part.Header.Get("Content-Type"),
+	}, nil
+}
+
+// Close releases the underlying response body
+func (m *MultipartDocument) Close() error {
+	return m.body.Close()
+}
+
@@ -0,0 +1,250 @@
+package couchdb
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// nodeCooldown is how long a node which failed a request is skipped before being tried again
+const nodeCooldown = 30 * time.Second
+
+// node is a single couchdb server of a cluster
+type node struct {
+	host string
+
+	mu      sync.Mutex
+	up      bool
+	retryAt time.Time
+}
+
+func (n *node) available(now time.Time) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.up || now.After(n.retryAt)
+}
+
+func (n *node) markUp() {
+	n.mu.Lock()
+	n.up = true
+	n.mu.Unlock()
+}
+
+func (n *node) markDown() {
+	n.mu.Lock()
+	n.up = false
+	n.retryAt = time.Now().Add(nodeCooldown)
+	n.mu.Unlock()
+}
+
+// nodePool spreads requests round-robin across all available nodes
+type nodePool struct {
+	mu    sync.RWMutex
+	nodes []*node
+	next  uint32
+}
+
+func newNodePool(hosts ...string) *nodePool {
+	p := &nodePool{}
+	p.add(hosts...)
+	return p
+}
+
+func (p *nodePool) add(hosts ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, host := range hosts {
+		host = strings.TrimRight(host, "/")
+		known := false
+		for _, n := range p.nodes {
+			known = known || n.host == host
+		}
+		if !known {
+			p.nodes = append(p.nodes, &node{host: host, up: true})
+		}
+	}
+}
+
+func (p *nodePool) all() []*node {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]*node(nil), p.nodes...)
+}
+
+// pick returns the next available node, or the next node at all if none is available
+func (p *nodePool) pick() *node {
+	nodes := p.all()
+	start := atomic.AddUint32(&p.next, 1)
+	now := time.Now()
+	for i := range nodes {
+		n := nodes[(int(start)+i)%len(nodes)]
+		if n.available(now) {
+			return n
+		}
+	}
+	return nodes[int(start)%len(nodes)]
+}
+
+// WithNodes adds further nodes of the same cluster next to the host passed to New.
+// Requests are spread across all nodes, failing over to the next node on connection errors.
+// Writes only fail over if they cannot have reached the failed node.
+func WithNodes(hosts ...string) func(*Client) error {
+	return func(c *Client) error {
+		if c.nodes == nil {
+			c.nodes = newNodePool(c.Host)
+		}
+		c.nodes.add(hosts...)
+		return nil
+	}
+}
+
+// WithNodeDiscovery makes the client look up all cluster nodes via GET /_membership on creation.
+// Nodes are assumed to be reachable using the scheme and port of the host passed to New.
+func WithNodeDiscovery() func(*Client) error {
+	return func(c *Client) error {
+		c.discoverNodes = true
+		return nil
+	}
+}
+
+// WithHealthCheck makes the client check every node with GET /_up in the given interval,
+// skipping nodes which are not up. Use Close to stop checking.
+func WithHealthCheck(interval time.Duration) func(*Client) error {
+	return func(c *Client) error {
+		if interval <= 0 {
+			return errors.New("couchdb: health check interval must be positive")
+		}
+		c.healthInterval = interval
+		if c.nodes == nil {
+			c.nodes = newNodePool(c.Host)
+		}
+		return nil
+	}
+}
+
+// DiscoverNodes adds all cluster nodes reported by GET /_membership to the client
+func (c *Client) DiscoverNodes() error {
+	m, err := c.Membership()
+	if err != nil {
+		return err
+	}
+	seed, err := url.Parse(c.Host)
+	if err != nil {
+		return err
+	}
+	hosts := []string{}
+	for _, name := range m.ClusterNodes {
+		// node names look like couchdb@10.0.0.1
+		hostname := name[strings.Index(name, "@")+1:]
+		u := *seed
+		u.Host = hostname
+		if port := seed.Port(); port != "" {
+			u.Host = hostname + ":" + port
+		}
+		hosts = append(hosts, u.String())
+	}
+	if c.nodes == nil {
+		c.nodes = newNodePool(c.Host)
+	}
+	c.nodes.add(hosts...)
+	return nil
+}
+
+// Nodes returns the hosts of all nodes known to the client
+func (c *Client) Nodes() []string {
+	if c.nodes == nil {
+		return []string{c.Host}
+	}
+	hosts := []string{}
+	for _, n := range c.nodes.all() {
+		hosts = append(hosts, n.host)
+	}
+	return hosts
+}
+
+// Close stops background health checks of the client's nodes
+func (c *Client) Close() error {
+	if c.stopHealth != nil {
+		close(c.stopHealth)
+		c.stopHealth = nil
+	}
+	return nil
+}
+
+// failover sends the request to an available node, moving on to the next one on connection
+// errors. Requests which may have reached the failed node are only sent again if they are reads:
+// a PUT or DELETE whose response was lost would fail with a conflict when sent again, although
+// it succeeded, and a POST might be applied twice. Errors preparing the request, e.g. obtaining
+// credentials, are returned right away.
+func (c *Client) failover(req *http.Request, auth Authentication) (*http.Response, error) {
+	if c.nodes == nil {
+		return c.roundTrip(req, auth, c.Host)
+	}
+	tries := len(c.nodes.all())
+	for {
+		n := c.nodes.pick()
+		resp, err := c.roundTrip(req, auth, n.host)
+		var decorateErr *decorateError
+		if err == nil || req.Context().Err() != nil || errors.As(err, &decorateErr) {
+			return resp, err
+		}
+		n.markDown()
+		tries--
+		if tries == 0 || !(unsent(err) || req.Method == "GET" || req.Method == "HEAD") || !rewind(req) {
+			return nil, err
+		}
+	}
+}
+
+// unsent reports whether err proves the request never reached the server, as no connection
+// could be established
+func unsent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func (c *Client) watchNodes(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			for _, n := range c.nodes.all() {
+				c.checkNode(n, interval)
+			}
+		}
+	}
+}
+
+// checkNode marks the node up or down depending on its GET /_up response
+func (c *Client) checkNode(n *node, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	req, err := http.NewRequest("GET", "/_up", nil)
+	if err != nil {
+		return
+	}
+	resp, err := c.roundTrip(req.WithContext(ctx), c.Authenticator, n.host)
+	var decorateErr *decorateError
+	if errors.As(err, &decorateErr) {
+		return
+	}
+	if err != nil {
+		n.markDown()
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		n.markDown()
+		return
+	}
+	n.markUp()
+}
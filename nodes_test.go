@@ -0,0 +1,170 @@
+// +build !integration
+
+package couchdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNodePool_pick(t *testing.T) {
+	t.Parallel()
+
+	p := newNodePool("http://a", "http://b/", "http://c", "http://a")
+	if len(p.all()) != 3 {
+		t.Fatalf("Expected 3 distinct nodes, got %d", len(p.all()))
+	}
+
+	picked := map[string]int{}
+	for i := 0; i < 6; i++ {
+		picked[p.pick().host]++
+	}
+	for _, host := range []string{"http://a", "http://b", "http://c"} {
+		if picked[host] != 2 {
+			t.Fatalf("Expected round robin across all nodes, got %v", picked)
+		}
+	}
+
+	p.all()[1].markDown()
+	for i := 0; i < 6; i++ {
+		if host := p.pick().host; host == "http://b" {
+			t.Fatal("Expected node which is down to be skipped")
+		}
+	}
+
+	for _, n := range p.all() {
+		n.markDown()
+	}
+	if p.pick() == nil {
+		t.Fatal("Expected a node to be picked if all nodes are down")
+	}
+}
+
+func TestNode_cooldown(t *testing.T) {
+	t.Parallel()
+
+	n := &node{host: "http://a", up: true}
+	now := time.Now()
+	if !n.available(now) {
+		t.Fatal("Expected new node to be available")
+	}
+	n.markDown()
+	if n.available(now) {
+		t.Fatal("Expected node to be unavailable during its cooldown")
+	}
+	if !n.available(now.Add(nodeCooldown + time.Second)) {
+		t.Fatal("Expected node to be tried again after its cooldown")
+	}
+	n.markUp()
+	if !n.available(now) {
+		t.Fatal("Expected node to be available once it is up")
+	}
+}
+
+func TestClient_DiscoverNodes(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/_membership" {
+			w.Write([]byte(`{"all_nodes":[],"cluster_nodes":["couchdb@127.0.0.1","couchdb@localhost"]}`))
+			return
+		}
+		w.Write([]byte(`{"version":"2.3.1"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, &http.Client{}, WithNodeDiscovery())
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := srv.URL[strings.LastIndex(srv.URL, ":"):]
+	expected := []string{srv.URL, "http://localhost" + port}
+	nodes := c.Nodes()
+	if len(nodes) != len(expected) || nodes[0] != expected[0] || nodes[1] != expected[1] {
+		t.Fatalf("Expected nodes %v, got %v", expected, nodes)
+	}
+}
+
+func TestClient_failover(t *testing.T) {
+	t.Parallel()
+
+	var liveHits int32
+	live := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&liveHits, 1)
+		w.Write([]byte(`{}`))
+	}))
+	defer live.Close()
+	// reset drops the connection after receiving the request, so the request may have been processed
+	reset := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	defer reset.Close()
+	// dead refuses connections, so requests never reach it
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+
+	for _, tc := range []struct {
+		name     string
+		first    string
+		method   string
+		failover bool
+	}{
+		{"unsent POST", dead.URL, "POST", true},
+		{"unsent GET", dead.URL, "GET", true},
+		{"unsent PUT", dead.URL, "PUT", true},
+		{"sent GET", reset.URL, "GET", true},
+		{"sent HEAD", reset.URL, "HEAD", true},
+		{"sent PUT", reset.URL, "PUT", false},
+		{"sent DELETE", reset.URL, "DELETE", false},
+		{"sent POST", reset.URL, "POST", false},
+	} {
+		atomic.StoreInt32(&liveHits, 0)
+		c := &Client{Host: tc.first, client: &http.Client{}, nodes: newNodePool(tc.first, live.URL)}
+		// make the first node the next one picked
+		c.nodes.next = 1
+
+		req, _ := http.NewRequest(tc.method, "/_bulk_docs", bytes.NewReader([]byte(`{"docs":[{}]}`)))
+		_, err := c.Do(req)
+		if tc.failover && (err != nil || atomic.LoadInt32(&liveHits) != 1) {
+			t.Fatalf("%s: expected request to fail over, got %v with %d requests", tc.name, err, liveHits)
+		}
+		if !tc.failover && (err == nil || atomic.LoadInt32(&liveHits) != 0) {
+			t.Fatalf("%s: expected request not to be sent again, got %v with %d requests", tc.name, err, liveHits)
+		}
+		if c.nodes.all()[0].available(time.Now()) {
+			t.Fatalf("%s: expected failed node to be marked down", tc.name)
+		}
+	}
+
+	// credentials which cannot be obtained are no fault of the node
+	atomic.StoreInt32(&liveHits, 0)
+	errNoToken := errors.New("token source unavailable")
+	tokens := 0
+	c := &Client{Host: live.URL, client: &http.Client{}, nodes: newNodePool(live.URL, strings.Replace(live.URL, "127.0.0.1", "localhost", 1))}
+	c.Authenticator = &JWTAuthentication{source: TokenSourceFunc(func(context.Context) (string, error) {
+		tokens++
+		return "", errNoToken
+	})}
+	req, _ := http.NewRequest("GET", "/_all_dbs", nil)
+	if _, err := c.Do(req); err != errNoToken {
+		t.Fatalf("Expected token source error to be returned, got %v", err)
+	}
+	if tokens != 1 || atomic.LoadInt32(&liveHits) != 0 {
+		t.Fatalf("Expected request to fail once without reaching couchdb, got %d tokens and %d requests", tokens, liveHits)
+	}
+	for _, n := range c.nodes.all() {
+		if !n.available(time.Now()) {
+			t.Fatal("Expected nodes to stay available after the token source failed")
+		}
+	}
+}
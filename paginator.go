@@ -0,0 +1,231 @@
+package couchdb
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidCursor is returned when resuming a paginator from a malformed cursor
+var ErrInvalidCursor = errors.New("couchdb: invalid cursor")
+
+// Page is a single page of view rows
+type Page struct {
+	Rows []Row
+	// Cursor continues with the next page, empty on the last page
+	Cursor string
+}
+
+// Paginator walks the rows of a view or _all_docs in pages. Instead of skipping rows, which
+// gets slower with every page, each page starts at the key and document id of the first row
+// not included in the previous page.
+//
+//   pages := db.ViewPaginator("company", "employees", couchdb.ViewOpts{}, 50)
+//   if err := pages.Resume(cursor); err != nil {
+//     return err
+//   }
+//   page, err := pages.Next(ctx)
+//   // … render page.Rows, passing page.Cursor to the client to fetch the next page
+type Paginator struct {
+	db   *Database
+	path string
+	opts ViewOpts
+	size int
+
+	cursor string
+	done   bool
+}
+
+type viewCursor struct {
+	Key   json.RawMessage `json:"k"`
+	DocID string          `json:"d,omitempty"`
+}
+
+// ViewPaginator pages through the rows of a view, size rows at a time
+func (d *Database) ViewPaginator(design, view string, opts ViewOpts, size int) *Paginator {
+	return &Paginator{
+		db:   d,
+		path: fmt.Sprintf("/_design/%s/_view/%s", design, view),
+		opts: opts,
+		size: size,
+	}
+}
+
+// AllDocsPaginator pages through all documents, size rows at a time
+func (d *Database) AllDocsPaginator(opts ViewOpts, size int) *Paginator {
+	return &Paginator{
+		db:   d,
+		path: "/_all_docs",
+		opts: opts,
+		size: size,
+	}
+}
+
+// Resume continues paging at the cursor of a previously returned page. An empty cursor
+// restarts at the first page.
+func (p *Paginator) Resume(cursor string) error {
+	if cursor != "" {
+		if _, err := decodeCursor(cursor); err != nil {
+			return err
+		}
+	}
+	p.cursor = cursor
+	p.done = false
+	return nil
+}
+
+// More reports whether there are pages left
+func (p *Paginator) More() bool {
+	return !p.done
+}
+
+// Cursor returns the cursor of the next page, empty once all pages were read
+func (p *Paginator) Cursor() string {
+	return p.cursor
+}
+
+// Next fetches the next page. After the last page it returns an empty page.
+func (p *Paginator) Next(ctx context.Context) (*Page, error) {
+	if p.size < 1 {
+		return nil, errors.New("couchdb: page size must be positive")
+	}
+	if p.opts.Keys != nil {
+		return nil, errors.New("couchdb: cannot paginate queries by keys")
+	}
+	if p.done {
+		return &Page{}, nil
+	}
+
+	opts := p.opts
+	opts.Limit = p.size + 1
+	if p.cursor != "" {
+		c, err := decodeCursor(p.cursor)
+		if err != nil {
+			return nil, err
+		}
+		opts.StartKey = c.Key
+		opts.StartKeyDocID = c.DocID
+		opts.Skip = 0
+	}
+
+	rows, err := p.db.rows(ctx, p.path, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &Page{}
+	for rows.Next() {
+		if len(page.Rows) < p.size {
+			page.Rows = append(page.Rows, rows.Row())
+			continue
+		}
+		// the row beyond the page is where the next page starts
+		cursor, err := encodeCursor(viewCursor{
+			Key:   rows.Row().Key,
+			DocID: rows.Row().ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		page.Cursor = cursor
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	p.cursor = page.Cursor
+	p.done = page.Cursor == ""
+	return page, nil
+}
+
+func encodeCursor(c viewCursor) (string, error) {
+	bs, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bs), nil
+}
+
+func decodeCursor(cursor string) (viewCursor, error) {
+	c := viewCursor{}
+	bs, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(bs, &c); err != nil || len(c.Key) == 0 {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// FindPage is a single page of Mango query results
+type FindPage struct {
+	Docs []json.RawMessage `json:"docs"`
+	// Bookmark continues with the next page, empty on the last page
+	Bookmark string `json:"bookmark"`
+}
+
+// FindPaginator walks the results of a Mango query in pages using bookmarks
+type FindPaginator struct {
+	db    *Database
+	query FindQuery
+
+	bookmark string
+	done     bool
+}
+
+// FindPaginator pages through the results of a Mango query, size documents at a time
+func (d *Database) FindPaginator(query FindQuery, size int) *FindPaginator {
+	query.Limit = size
+	return &FindPaginator{
+		db:       d,
+		query:    query,
+		bookmark: query.Bookmark,
+	}
+}
+
+// Resume continues paging at the bookmark of a previously returned page. An empty bookmark
+// restarts at the first page.
+func (p *FindPaginator) Resume(bookmark string) {
+	p.bookmark = bookmark
+	p.done = false
+}
+
+// More reports whether there are pages left
+func (p *FindPaginator) More() bool {
+	return !p.done
+}
+
+// Bookmark returns the bookmark of the next page, empty once all pages were read
+func (p *FindPaginator) Bookmark() string {
+	return p.bookmark
+}
+
+// Next fetches the next page. After the last page it returns an empty page.
+func (p *FindPaginator) Next(ctx context.Context) (*FindPage, error) {
+	if p.query.Limit < 1 {
+		return nil, errors.New("couchdb: page size must be positive")
+	}
+	if p.done {
+		return &FindPage{}, nil
+	}
+
+	query := p.query
+	query.Bookmark = p.bookmark
+	query.Skip = 0
+	page := &FindPage{}
+	if _, err := p.db.Find(ctx, query, page); err != nil {
+		return nil, err
+	}
+	// couchdb keeps returning a bookmark on the last page, which a full page cannot tell apart
+	// from further results, so the next request may yield an empty page
+	if len(page.Docs) < query.Limit {
+		page.Bookmark = ""
+	}
+	p.bookmark = page.Bookmark
+	p.done = page.Bookmark == ""
+	return page, nil
+}
@@ -0,0 +1,68 @@
+// +build !integration
+
+package couchdb
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDatabase_AllDocsPaginator(t *testing.T) {
+	t.Parallel()
+
+	pages := playground.AllDocsPaginator(ViewOpts{
+		StartKey: "employee:",
+		EndKey:   "employee:{}",
+	}, 1)
+	first, err := pages.Next(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Rows) != 1 || first.Rows[0].ID != "employee:michael" || first.Cursor == "" {
+		t.Fatalf("Unexpected first page %#v", first)
+	}
+
+	resumed := playground.AllDocsPaginator(ViewOpts{
+		StartKey: "employee:",
+		EndKey:   "employee:{}",
+	}, 1)
+	if err := resumed.Resume(first.Cursor); err != nil {
+		t.Fatal(err)
+	}
+	second, err := resumed.Next(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second.Rows) != 1 || second.Rows[0].ID != "employee:raphael" {
+		t.Fatalf("Unexpected second page %#v", second)
+	}
+	if second.Cursor != "" || resumed.More() {
+		t.Fatal("Expected the second page to be the last one")
+	}
+
+	if err := resumed.Resume("garbage"); err != ErrInvalidCursor {
+		t.Fatalf("Expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestDatabase_FindPaginator(t *testing.T) {
+	t.Parallel()
+	if !client.CouchDB.HasClusterSupport() {
+		t.Skip("_find requires couchdb 2.x")
+	}
+
+	pages := playground.FindPaginator(FindQuery{
+		Selector: In("name", "Michael", "Yumi"),
+	}, 1)
+	docs := 0
+	for pages.More() {
+		page, err := pages.Next(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		docs += len(page.Docs)
+	}
+	if docs != 2 {
+		t.Fatalf("Expected 2 docs, got %d", docs)
+	}
+}
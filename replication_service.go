@@ -29,6 +29,8 @@ type Replication struct {
 	Context                *UserContext      `json:"user_ctx,omitempty"`
 	Filter                 string            `json:"filter,omitempty"`
 	QueryParams            map[string]string `json:"query_params,omitempty"`
+	DocIDs                 []string          `json:"doc_ids,omitempty"`
+	Selector               interface{}       `json:"selector,omitempty"`
 }
 
 type ReplicationPayload struct {
@@ -39,6 +41,8 @@ type ReplicationPayload struct {
 	CreateTarget bool
 	Filter       string
 	QueryParams  map[string]string
+	DocIDs       []string
+	Selector     interface{}
 	Context      *UserContext
 }
 
@@ -52,6 +56,8 @@ func (c *ReplicationService) Create(ctx context.Context, p ReplicationPayload) (
 		Continuous:   p.Continuous,
 		Filter:       p.Filter,
 		QueryParams:  p.QueryParams,
+		DocIDs:       p.DocIDs,
+		Selector:     p.Selector,
 		Context:      p.Context,
 	}
 	_, err := db.Put(context.Background(), p.ID, rep)
@@ -82,6 +88,8 @@ func (c *ReplicationService) Update(ctx context.Context, p ReplicationPayload) (
 		Continuous:   p.Continuous,
 		Filter:       p.Filter,
 		QueryParams:  p.QueryParams,
+		DocIDs:       p.DocIDs,
+		Selector:     p.Selector,
 		Context:      p.Context,
 	}
 	_, err = db.Put(context.Background(), p.ID, rep)
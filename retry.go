@@ -0,0 +1,167 @@
+package couchdb
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls which failed requests are sent again, and how long to wait in between
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts made for a single request, including the first one
+	MaxAttempts int
+	// MinBackoff is the wait before the first retry. It doubles with every further attempt,
+	// so it must be positive.
+	MinBackoff time.Duration
+	// MaxBackoff caps the wait between two attempts
+	MaxBackoff time.Duration
+	// Methods lists the HTTP methods which are safe to retry
+	Methods []string
+	// StatusCodes lists the HTTP status codes which are considered transient
+	StatusCodes []int
+}
+
+// DefaultRetryPolicy retries reads on connection errors, rate limiting and unavailable nodes.
+// Writes are not retried by default: if the response to a PUT or DELETE is lost, sending it
+// again fails with ErrConflict although the write succeeded. Callers handling this may opt in
+// by adding the methods to a copy of the policy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  100 * time.Millisecond,
+	MaxBackoff:  5 * time.Second,
+	Methods:     []string{"GET", "HEAD"},
+	StatusCodes: []int{
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+// WithRetryPolicy makes the client retry failed requests according to the given policy.
+// Requests are only retried if their body can be replayed, which is the case for bodies
+// passed to http.NewRequest as *bytes.Buffer, *bytes.Reader or *strings.Reader.
+func WithRetryPolicy(policy RetryPolicy) func(*Client) error {
+	return func(c *Client) error {
+		if policy.MaxAttempts < 1 {
+			return errors.New("couchdb: retry policy requires at least one attempt")
+		}
+		if policy.MinBackoff <= 0 {
+			return errors.New("couchdb: retry policy requires a positive MinBackoff")
+		}
+		if policy.MaxBackoff < policy.MinBackoff {
+			return errors.New("couchdb: retry policy MaxBackoff must not be less than MinBackoff")
+		}
+		c.retryPolicy = &policy
+		return nil
+	}
+}
+
+func (p *RetryPolicy) retryable(req *http.Request, resp *http.Response, err error) bool {
+	if !p.allowsMethod(req.Method) || !replayable(req) {
+		return false
+	}
+	if err != nil {
+		// errors caused by the caller giving up must not be retried
+		return req.Context().Err() == nil && transportError(err)
+	}
+	for _, code := range p.StatusCodes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// transportError reports whether err was raised sending the request, as opposed to preparing
+// it, e.g. failing to obtain credentials
+func transportError(err error) bool {
+	var decorateErr *decorateError
+	if errors.As(err, &decorateErr) {
+		return false
+	}
+	var urlErr *url.Error
+	var netErr net.Error
+	return errors.As(err, &urlErr) || errors.As(err, &netErr)
+}
+
+func (p *RetryPolicy) allowsMethod(method string) bool {
+	for _, m := range p.Methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the wait before the given retry, honoring a Retry-After header if present
+func (p *RetryPolicy) backoff(retry int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			wait := time.Duration(seconds) * time.Second
+			if wait > p.MaxBackoff {
+				wait = p.MaxBackoff
+			}
+			return wait
+		}
+	}
+
+	wait := p.MinBackoff
+	for i := 1; i < retry && wait < p.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	// use half of the wait as jitter so concurrent clients do not retry in lockstep
+	if half := int64(wait / 2); half > 0 {
+		wait = time.Duration(half + rand.Int63n(half))
+	}
+	return wait
+}
+
+// sleep waits for d, returning early with the context's error if it is done first
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// send performs the request, retrying it as permitted by the client's retry policy
+func (c *Client) send(req *http.Request, auth Authentication) (*http.Response, error) {
+	policy := c.retryPolicy
+	for attempt := 1; ; attempt++ {
+		resp, err := c.failover(req, auth)
+		if policy == nil || attempt >= policy.MaxAttempts || !policy.retryable(req, resp, err) {
+			return resp, err
+		}
+
+		wait := policy.backoff(attempt, resp)
+		if deadline, ok := req.Context().Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+		if !rewind(req) {
+			return nil, errors.New("couchdb: failed to replay request body")
+		}
+	}
+}
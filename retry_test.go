@@ -0,0 +1,233 @@
+// +build !integration
+
+package couchdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	for name, policy := range map[string]RetryPolicy{
+		"no attempts":      {MaxAttempts: 0, MinBackoff: time.Second, MaxBackoff: time.Second},
+		"no backoff":       {MaxAttempts: 3, MinBackoff: 0, MaxBackoff: time.Second},
+		"inverted backoff": {MaxAttempts: 3, MinBackoff: time.Second, MaxBackoff: time.Millisecond},
+	} {
+		if err := WithRetryPolicy(policy)(&Client{}); err == nil {
+			t.Fatalf("Expected policy with %s to be rejected", name)
+		}
+	}
+	if err := WithRetryPolicy(DefaultRetryPolicy)(&Client{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	t.Parallel()
+
+	p := RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for retry, max := range map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 400 * time.Millisecond,
+		4: 800 * time.Millisecond,
+		5: time.Second,
+		9: time.Second,
+	} {
+		for i := 0; i < 20; i++ {
+			// half of the wait is jitter
+			if wait := p.backoff(retry, nil); wait < max/2 || wait >= max {
+				t.Fatalf("Expected retry %d to wait in [%v, %v), got %v", retry, max/2, max, wait)
+			}
+		}
+	}
+
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Retry-After", "0")
+	if wait := p.backoff(3, resp); wait != 0 {
+		t.Fatalf("Expected Retry-After to be honored, got %v", wait)
+	}
+	resp.Header.Set("Retry-After", "120")
+	if wait := p.backoff(1, resp); wait != time.Second {
+		t.Fatalf("Expected Retry-After to be capped at MaxBackoff, got %v", wait)
+	}
+	resp.Header.Set("Retry-After", "Wed, 21 Oct 2015 07:28:00 GMT")
+	if wait := p.backoff(1, resp); wait < 50*time.Millisecond || wait >= 100*time.Millisecond {
+		t.Fatalf("Expected unparsable Retry-After to be ignored, got %v", wait)
+	}
+}
+
+func TestRetryPolicy_retryable(t *testing.T) {
+	t.Parallel()
+
+	for _, method := range []string{"PUT", "DELETE", "POST"} {
+		if DefaultRetryPolicy.allowsMethod(method) {
+			t.Fatalf("Expected %s not to be retried by default", method)
+		}
+	}
+
+	p := DefaultRetryPolicy
+	p.Methods = append([]string{"PUT"}, p.Methods...)
+	status := func(code int) *http.Response { return &http.Response{StatusCode: code} }
+	connErr := &url.Error{Op: "Get", URL: "http://localhost:5984/db", Err: errors.New("connection reset")}
+	get, _ := http.NewRequest("GET", "/db", nil)
+	put, _ := http.NewRequest("PUT", "/db/doc", strings.NewReader("{}"))
+	post, _ := http.NewRequest("POST", "/db/_bulk_docs", strings.NewReader("{}"))
+	stream, _ := http.NewRequest("PUT", "/db/doc", ioutil.NopCloser(strings.NewReader("{}")))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, tc := range []struct {
+		name      string
+		req       *http.Request
+		resp      *http.Response
+		err       error
+		retryable bool
+	}{
+		{"unavailable", get, status(http.StatusServiceUnavailable), nil, true},
+		{"rate limited", put, status(http.StatusTooManyRequests), nil, true},
+		{"connection error", get, nil, connErr, true},
+		{"credentials unavailable", get, nil, &decorateError{errors.New("token source unavailable")}, false},
+		{"login failed", get, nil, &decorateError{connErr}, false},
+		{"other error", get, nil, errors.New("invalid request"), false},
+		{"not found", get, status(http.StatusNotFound), nil, false},
+		{"conflict", put, status(http.StatusConflict), nil, false},
+		{"post", post, status(http.StatusServiceUnavailable), nil, false},
+		{"body not replayable", stream, status(http.StatusServiceUnavailable), nil, false},
+		{"cancelled", get.WithContext(ctx), nil, context.Canceled, false},
+	} {
+		if p.retryable(tc.req, tc.resp, tc.err) != tc.retryable {
+			t.Fatalf("%s: expected retryable to be %v", tc.name, tc.retryable)
+		}
+	}
+}
+
+// flakyServer answers the first failures requests with 503, recording all request bodies
+func flakyServer(failures int32, header http.Header) (*httptest.Server, *[]string) {
+	var attempts int32
+	bodies := &[]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bs, _ := ioutil.ReadAll(r.Body)
+		*bodies = append(*bodies, string(bs))
+		if atomic.AddInt32(&attempts, 1) <= failures {
+			for key := range header {
+				w.Header().Set(key, header.Get(key))
+			}
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error":"unavailable","reason":"try again"}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	return srv, bodies
+}
+
+func TestClient_retry(t *testing.T) {
+	t.Parallel()
+
+	policy := RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  10 * time.Millisecond,
+		Methods:     []string{"PUT"},
+		StatusCodes: []int{http.StatusServiceUnavailable},
+	}
+
+	t.Run("replays body", func(t *testing.T) {
+		srv, bodies := flakyServer(2, nil)
+		defer srv.Close()
+		c := &Client{Host: srv.URL, client: &http.Client{}, retryPolicy: &policy}
+		req, _ := http.NewRequest("PUT", "/db/doc", bytes.NewReader([]byte(`{"name":"Yumi"}`)))
+		if _, err := c.Do(req); err != nil {
+			t.Fatal(err)
+		}
+		if len(*bodies) != 3 {
+			t.Fatalf("Expected 3 attempts, got %d", len(*bodies))
+		}
+		for _, body := range *bodies {
+			if body != `{"name":"Yumi"}` {
+				t.Fatalf("Expected body to be replayed, got %q", body)
+			}
+		}
+	})
+
+	t.Run("gives up", func(t *testing.T) {
+		srv, bodies := flakyServer(5, nil)
+		defer srv.Close()
+		c := &Client{Host: srv.URL, client: &http.Client{}, retryPolicy: &policy}
+		req, _ := http.NewRequest("PUT", "/db/doc", nil)
+		_, err := c.Do(req)
+		var apiErr *Error
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable || len(*bodies) != 3 {
+			t.Fatalf("Expected to give up after 3 attempts, got %v after %d", err, len(*bodies))
+		}
+	})
+
+	t.Run("honors Retry-After", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("Retry-After", "1")
+		srv, bodies := flakyServer(1, header)
+		defer srv.Close()
+		slow := policy
+		slow.MaxBackoff = 200 * time.Millisecond
+		c := &Client{Host: srv.URL, client: &http.Client{}, retryPolicy: &slow}
+		req, _ := http.NewRequest("PUT", "/db/doc", nil)
+		start := time.Now()
+		if _, err := c.Do(req); err != nil {
+			t.Fatal(err)
+		}
+		// Retry-After is capped at MaxBackoff, which is not subject to jitter
+		if elapsed := time.Since(start); elapsed < 200*time.Millisecond || len(*bodies) != 2 {
+			t.Fatalf("Expected to wait for Retry-After, retried after %v", elapsed)
+		}
+	})
+
+	t.Run("credentials unavailable", func(t *testing.T) {
+		srv, bodies := flakyServer(0, nil)
+		defer srv.Close()
+		tokens := 0
+		errNoToken := errors.New("token source unavailable")
+		c := &Client{Host: srv.URL, client: &http.Client{}, retryPolicy: &policy}
+		c.Authenticator = &JWTAuthentication{source: TokenSourceFunc(func(context.Context) (string, error) {
+			tokens++
+			return "", errNoToken
+		})}
+		req, _ := http.NewRequest("PUT", "/db/doc", nil)
+		if _, err := c.Do(req); err != errNoToken {
+			t.Fatalf("Expected token source error, got %v", err)
+		}
+		if tokens != 1 || len(*bodies) != 0 {
+			t.Fatalf("Expected no retries, got %d tokens and %d requests", tokens, len(*bodies))
+		}
+	})
+
+	t.Run("respects deadline", func(t *testing.T) {
+		srv, bodies := flakyServer(5, nil)
+		defer srv.Close()
+		slow := policy
+		slow.MinBackoff, slow.MaxBackoff = time.Second, time.Second
+		c := &Client{Host: srv.URL, client: &http.Client{}, retryPolicy: &slow}
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		req, _ := http.NewRequest("PUT", "/db/doc", nil)
+		start := time.Now()
+		resp, err := c.Do(req.WithContext(ctx))
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond || len(*bodies) != 1 {
+			t.Fatalf("Expected to stop retrying before the deadline, took %v for %d attempts", elapsed, len(*bodies))
+		}
+		if err == nil || resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("Expected the last response to be returned, got %v", err)
+		}
+	})
+}
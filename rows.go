@@ -0,0 +1,227 @@
+package couchdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Row is a single row of a view or _all_docs response
+type Row struct {
+	ID    string          `json:"id"`
+	Key   json.RawMessage `json:"key"`
+	Value json.RawMessage `json:"value"`
+	Doc   json.RawMessage `json:"doc"`
+	Error string          `json:"error"`
+}
+
+// Rows iterates over the rows of a view or _all_docs response, decoding one row at a time so
+// arbitrarily large results can be processed in constant memory.
+//
+//   rows, err := db.ViewRows(ctx, "company", "employees", couchdb.ViewOpts{})
+//   if err != nil {
+//     return err
+//   }
+//   defer rows.Close()
+//   for rows.Next() {
+//     var employee Employee
+//     if err := rows.ScanValue(&employee); err != nil {
+//       return err
+//     }
+//   }
+//   return rows.Err()
+type Rows struct {
+	body io.ReadCloser
+	dec  *json.Decoder
+	row  Row
+	err  error
+	done bool
+
+	totalRows int
+	offset    int
+	updateSeq Sequence
+}
+
+// ViewRows queries a view, returning an iterator over its rows. GET /{db}/_design/{design}/_view/{view}
+func (d *Database) ViewRows(ctx context.Context, design, view string, opts ViewOpts) (*Rows, error) {
+	return d.rows(ctx, fmt.Sprintf("/_design/%s/_view/%s", design, view), opts)
+}
+
+// AllDocsRows queries all documents, returning an iterator over the rows. GET /{db}/_all_docs
+func (d *Database) AllDocsRows(ctx context.Context, opts ViewOpts) (*Rows, error) {
+	return d.rows(ctx, "/_all_docs", opts)
+}
+
+func (d *Database) rows(ctx context.Context, path string, opts ViewOpts) (*Rows, error) {
+	req, err := d.viewRequest(ctx, path, opts)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, newError(req, resp, nil)
+	}
+
+	r := &Rows{
+		body: resp.Body,
+		dec:  json.NewDecoder(resp.Body),
+	}
+	// metadata preceding the rows is available right away
+	if err := r.expect(json.Delim('{')); err != nil {
+		r.Close()
+		return nil, err
+	}
+	if err := r.readFields(); err != nil {
+		r.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Rows) expect(delim json.Delim) error {
+	token, err := r.dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("couchdb: unexpected %v in rows response", token)
+	}
+	return nil
+}
+
+// readFields decodes top level fields until the rows array starts or the response ends
+func (r *Rows) readFields() error {
+	for r.dec.More() {
+		token, err := r.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case "rows":
+			return r.expect(json.Delim('['))
+		case "total_rows":
+			err = r.dec.Decode(&r.totalRows)
+		case "offset":
+			err = r.dec.Decode(&r.offset)
+		case "update_seq":
+			err = r.dec.Decode(&r.updateSeq)
+		default:
+			var skip json.RawMessage
+			err = r.dec.Decode(&skip)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	r.done = true
+	return nil
+}
+
+// Next advances to the next row, returning false once all rows were read or decoding failed
+func (r *Rows) Next() bool {
+	if r.done || r.err != nil {
+		return false
+	}
+	if r.dec.More() {
+		row := Row{}
+		if err := r.dec.Decode(&row); err != nil {
+			r.err = err
+			return false
+		}
+		r.row = row
+		return true
+	}
+	// consume the end of the rows and any metadata following them
+	if err := r.expect(json.Delim(']')); err != nil {
+		r.err = err
+		return false
+	}
+	if err := r.readFields(); err != nil {
+		r.err = err
+	}
+	r.done = true
+	return false
+}
+
+// Row returns the current row
+func (r *Rows) Row() Row {
+	return r.row
+}
+
+// ID returns the document id of the current row
+func (r *Rows) ID() string {
+	return r.row.ID
+}
+
+// RowErr returns the error reported for the current row, e.g. for keys which were not found
+func (r *Rows) RowErr() error {
+	if r.row.Error == "" {
+		return nil
+	}
+	return &Error{
+		ErrorResponse: ErrorResponse{
+			Type:   r.row.Error,
+			Reason: fmt.Sprintf("key %s: %s", r.row.Key, r.row.Error),
+		},
+		StatusCode: bulkErrorStatus[r.row.Error],
+	}
+}
+
+// ScanKey decodes the key of the current row into key
+func (r *Rows) ScanKey(key interface{}) error {
+	return scanRow(r.row.Key, key)
+}
+
+// ScanValue decodes the value of the current row into value
+func (r *Rows) ScanValue(value interface{}) error {
+	return scanRow(r.row.Value, value)
+}
+
+// ScanDoc decodes the document of the current row, included using IncludeDocs, into doc
+func (r *Rows) ScanDoc(doc interface{}) error {
+	if len(r.row.Doc) == 0 {
+		return errors.New("couchdb: row does not include a document")
+	}
+	return scanRow(r.row.Doc, doc)
+}
+
+func scanRow(data json.RawMessage, v interface{}) error {
+	if len(data) == 0 {
+		data = json.RawMessage("null")
+	}
+	return json.Unmarshal(data, v)
+}
+
+// TotalRows returns the number of rows in the view, ignoring limits
+func (r *Rows) TotalRows() int {
+	return r.totalRows
+}
+
+// Offset returns the position of the first row within the view
+func (r *Rows) Offset() int {
+	return r.offset
+}
+
+// UpdateSeq returns the sequence the view was updated to, if requested using UpdateSeq. couchdb
+// may send it after the rows, in which case it is only available once Next returned false.
+func (r *Rows) UpdateSeq() Sequence {
+	return r.updateSeq
+}
+
+// Err returns the error which ended the iteration, if any
+func (r *Rows) Err() error {
+	return r.err
+}
+
+// Close releases the response. It must be called if the iteration is stopped early.
+func (r *Rows) Close() error {
+	r.done = true
+	return r.body.Close()
+}
@@ -0,0 +1,128 @@
+package couchdb
+
+// Selector is a Mango selector. Use the functions below to build selectors instead of
+// writing map literals:
+//
+//   couchdb.And(
+//     couchdb.Eq("type", "employee"),
+//     couchdb.Gte("age", 18),
+//     couchdb.In("department", "sales", "marketing"),
+//   )
+//
+// Passing an empty field creates an operator-only condition, which matches elements of arrays
+// inside ElemMatch and AllMatch:
+//
+//   couchdb.ElemMatch("tags", couchdb.Regex("", "^sales")) // {"tags": {"$elemMatch": {"$regex": "^sales"}}}
+type Selector map[string]interface{}
+
+func condition(field, operator string, value interface{}) Selector {
+	if field == "" {
+		return Selector{operator: value}
+	}
+	return Selector{field: map[string]interface{}{operator: value}}
+}
+
+// Eq matches documents whose field equals value. {"field": {"$eq": value}}
+func Eq(field string, value interface{}) Selector {
+	return condition(field, "$eq", value)
+}
+
+// Ne matches documents whose field does not equal value. {"field": {"$ne": value}}
+func Ne(field string, value interface{}) Selector {
+	return condition(field, "$ne", value)
+}
+
+// Gt matches documents whose field is greater than value. {"field": {"$gt": value}}
+func Gt(field string, value interface{}) Selector {
+	return condition(field, "$gt", value)
+}
+
+// Gte matches documents whose field is greater than or equal to value. {"field": {"$gte": value}}
+func Gte(field string, value interface{}) Selector {
+	return condition(field, "$gte", value)
+}
+
+// Lt matches documents whose field is less than value. {"field": {"$lt": value}}
+func Lt(field string, value interface{}) Selector {
+	return condition(field, "$lt", value)
+}
+
+// Lte matches documents whose field is less than or equal to value. {"field": {"$lte": value}}
+func Lte(field string, value interface{}) Selector {
+	return condition(field, "$lte", value)
+}
+
+// In matches documents whose field equals one of values. {"field": {"$in": values}}
+func In(field string, values ...interface{}) Selector {
+	return condition(field, "$in", values)
+}
+
+// Nin matches documents whose field equals none of values. {"field": {"$nin": values}}
+func Nin(field string, values ...interface{}) Selector {
+	return condition(field, "$nin", values)
+}
+
+// All matches documents whose array field contains all values. {"field": {"$all": values}}
+func All(field string, values ...interface{}) Selector {
+	return condition(field, "$all", values)
+}
+
+// Exists matches documents which do, or do not, have the field. {"field": {"$exists": exists}}
+func Exists(field string, exists bool) Selector {
+	return condition(field, "$exists", exists)
+}
+
+// Type matches documents whose field has the given JSON type, e.g. "string" or "array".
+// {"field": {"$type": typ}}
+func Type(field, typ string) Selector {
+	return condition(field, "$type", typ)
+}
+
+// Size matches documents whose array field has the given length. {"field": {"$size": size}}
+func Size(field string, size int) Selector {
+	return condition(field, "$size", size)
+}
+
+// Mod matches documents whose integer field divided by divisor leaves remainder.
+// {"field": {"$mod": [divisor, remainder]}}
+func Mod(field string, divisor, remainder int) Selector {
+	return condition(field, "$mod", []int{divisor, remainder})
+}
+
+// Regex matches documents whose string field matches the erlang regular expression pattern.
+// {"field": {"$regex": pattern}}
+func Regex(field, pattern string) Selector {
+	return condition(field, "$regex", pattern)
+}
+
+// ElemMatch matches documents whose array field contains at least one element matching s.
+// Use an empty field in s to match elements which are not objects. {"field": {"$elemMatch": s}}
+func ElemMatch(field string, s Selector) Selector {
+	return condition(field, "$elemMatch", s)
+}
+
+// AllMatch matches documents whose array field only contains elements matching s.
+// Use an empty field in s to match elements which are not objects. {"field": {"$allMatch": s}}
+func AllMatch(field string, s Selector) Selector {
+	return condition(field, "$allMatch", s)
+}
+
+// And matches documents matching all selectors. {"$and": selectors}
+func And(selectors ...Selector) Selector {
+	return Selector{"$and": selectors}
+}
+
+// Or matches documents matching at least one of the selectors. {"$or": selectors}
+func Or(selectors ...Selector) Selector {
+	return Selector{"$or": selectors}
+}
+
+// Nor matches documents matching none of the selectors. {"$nor": selectors}
+func Nor(selectors ...Selector) Selector {
+	return Selector{"$nor": selectors}
+}
+
+// Not matches documents not matching s. {"$not": s}
+func Not(s Selector) Selector {
+	return Selector{"$not": s}
+}
@@ -1,8 +1,10 @@
 package couchdb
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"net/http"
 )
@@ -45,3 +47,51 @@ func (s *SessionService) Get(ctx context.Context) (*Session, error) {
 	}
 	return &sess, nil
 }
+
+type sessionCredentials struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+// Login creates a new cookie based session for the given credentials. POST /_session
+func (s *SessionService) Login(ctx context.Context, username, password string) (*http.Cookie, error) {
+	bs, err := json.Marshal(sessionCredentials{
+		Name:     username,
+		Password: password,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", "/_session", bytes.NewReader(bs))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	resp, err := s.c.do(req, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == sessionCookieName {
+			return cookie, nil
+		}
+	}
+	return nil, errors.New("couchdb: POST /_session did not return a session cookie")
+}
+
+// Logout ends the session identified by the given cookie. DELETE /_session
+func (s *SessionService) Logout(ctx context.Context, cookie *http.Cookie) error {
+	req, err := http.NewRequest("DELETE", "/_session", nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.AddCookie(cookie)
+	resp, err := s.c.do(req, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,49 @@
+// +build !integration
+
+package couchdb
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestSessionService_Login(t *testing.T) {
+	if os.Getenv("COUCHDB_USERNAME") == "" {
+		t.Skip("COUCHDB_USERNAME is not configured")
+	}
+
+	cookie, err := client.Sessions.Login(context.Background(), os.Getenv("COUCHDB_USERNAME"), os.Getenv("COUCHDB_PASSWORD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cookie.Value == "" {
+		t.Fatal("Expected a session cookie, but got nothing")
+	}
+	if err := client.Sessions.Logout(context.Background(), cookie); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCookieAuthentication(t *testing.T) {
+	if os.Getenv("COUCHDB_USERNAME") == "" {
+		t.Skip("COUCHDB_USERNAME is not configured")
+	}
+
+	c, err := New(os.Getenv("COUCHDB_HOST_PORT"), &http.Client{}, WithCookieAuthentication(os.Getenv("COUCHDB_USERNAME"), os.Getenv("COUCHDB_PASSWORD")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sess, err := c.Sessions.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sess.Context.Name != os.Getenv("COUCHDB_USERNAME") {
+		t.Fatalf("Expected session for %q, but got %q", os.Getenv("COUCHDB_USERNAME"), sess.Context.Name)
+	}
+	if sess.Info.Authenticated != "cookie" {
+		t.Fatalf("Expected cookie authentication, but got %q", sess.Info.Authenticated)
+	}
+}
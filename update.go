@@ -0,0 +1,101 @@
+package couchdb
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// DefaultUpdateRetries is the number of times Update retries after conflicts by default
+const DefaultUpdateRetries = 5
+
+// UpdateOpts defines how Update handles missing documents and conflicts
+type UpdateOpts struct {
+	// MaxRetries limits how often the update is retried after conflicts, defaulting to
+	// DefaultUpdateRetries. A negative value disables retries.
+	MaxRetries int
+	// CreateIfMissing applies the mutation to an empty document if none exists, instead of
+	// failing with ErrNotFound
+	CreateIfMissing bool
+}
+
+// identifiable is implemented by all structs embedding Document
+type identifiable interface {
+	identify(id, rev string)
+}
+
+func (d *Document) identify(id, rev string) {
+	d.ID = id
+	d.Rev = rev
+}
+
+// Update fetches a document into doc, applies mutate and writes it back, returning the new
+// revision. If the document was changed concurrently, doc is fetched and mutated again, so
+// mutate must not depend on state of previous attempts. doc must be a pointer to a struct
+// embedding Document or *Document.
+//
+//   var employee Employee
+//   rev, err := db.Update(ctx, "employee:michael", &employee, func(doc interface{}) error {
+//     employee.Title = "CTO"
+//     return nil
+//   }, couchdb.UpdateOpts{})
+func (d *Database) Update(ctx context.Context, id string, doc interface{}, mutate func(interface{}) error, opts UpdateOpts) (string, error) {
+	target, ok := doc.(identifiable)
+	v := reflect.ValueOf(doc)
+	if !ok || v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return "", errors.New("couchdb: Update requires a pointer to a struct embedding Document")
+	}
+	retries := opts.MaxRetries
+	if retries == 0 {
+		retries = DefaultUpdateRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		// fields removed from the stored document must not survive from previous attempts
+		reset(v.Elem())
+		err := d.Get(ctx, id, doc)
+		if errors.Is(err, ErrNotFound) && opts.CreateIfMissing {
+			reset(v.Elem())
+			target.identify(id, "")
+			err = nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if err := mutate(doc); err != nil {
+			return "", err
+		}
+		rev, err := d.Put(ctx, id, doc)
+		if err == nil {
+			target.identify(id, rev)
+			return rev, nil
+		}
+		if !errors.Is(err, ErrConflict) || attempt >= retries {
+			return "", err
+		}
+	}
+}
+
+// reset zeroes a struct, allocating embedded struct pointers such as *Document so promoted
+// methods can be called on the result
+func reset(v reflect.Value) {
+	v.Set(reflect.Zero(v.Type()))
+	allocateEmbedded(v)
+}
+
+func allocateEmbedded(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field, value := v.Type().Field(i), v.Field(i)
+		if !field.Anonymous || !value.CanSet() {
+			continue
+		}
+		switch {
+		case field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct:
+			value.Set(reflect.New(field.Type.Elem()))
+			allocateEmbedded(value.Elem())
+		case field.Type.Kind() == reflect.Struct:
+			allocateEmbedded(value)
+		}
+	}
+}
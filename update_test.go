@@ -0,0 +1,157 @@
+// +build !integration
+
+package couchdb
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type counterDoc struct {
+	Document
+	Count int `json:"count"`
+}
+
+type counterPtrDoc struct {
+	*Document
+	Count int `json:"count"`
+}
+
+func TestDatabase_Update(t *testing.T) {
+	t.Parallel()
+
+	db := client.Database("update-test")
+	client.Databases.Create(db.Name, DatabaseClusterOptions{})
+	defer client.Databases.Delete(db.Name)
+
+	increment := func(doc interface{}) error {
+		doc.(*counterDoc).Count++
+		return nil
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		var doc counterDoc
+		_, err := db.Update(context.Background(), "counter:missing", &doc, increment, UpdateOpts{})
+		if !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var doc counterDoc
+				if _, err := db.Update(context.Background(), "counter:concurrent", &doc, increment, UpdateOpts{
+					MaxRetries:      10,
+					CreateIfMissing: true,
+				}); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		var doc counterDoc
+		if err := db.Get(context.Background(), "counter:concurrent", &doc); err != nil {
+			t.Fatal(err)
+		}
+		if doc.Count != 5 {
+			t.Fatalf("Expected count of 5, got %d", doc.Count)
+		}
+	})
+}
+
+func TestDatabase_Update_CreateIfMissing(t *testing.T) {
+	t.Parallel()
+
+	var created string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"not_found","reason":"missing"}`))
+			return
+		}
+		bs, _ := ioutil.ReadAll(r.Body)
+		created = string(bs)
+		w.Header().Set("Etag", `"1-a"`)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"ok":true,"id":"counter","rev":"1-a"}`))
+	}))
+	defer srv.Close()
+	db := (&Client{Host: srv.URL, client: &http.Client{}}).Database("update-test")
+
+	for _, tc := range []struct {
+		name string
+		doc  interface{}
+	}{
+		{"embedded Document", &counterDoc{Count: 7}},
+		{"embedded *Document", &counterPtrDoc{Count: 7}},
+	} {
+		rev, err := db.Update(context.Background(), "counter", tc.doc, func(doc interface{}) error {
+			switch doc := doc.(type) {
+			case *counterDoc:
+				doc.Count++
+			case *counterPtrDoc:
+				doc.Count++
+			}
+			return nil
+		}, UpdateOpts{CreateIfMissing: true})
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if rev != "1-a" {
+			t.Fatalf("%s: expected revision 1-a, got %q", tc.name, rev)
+		}
+		if expected := `{"_id":"counter","count":1}`; created != expected {
+			t.Fatalf("%s: expected %s to be created, got %s", tc.name, expected, created)
+		}
+	}
+}
+
+func TestDatabase_Update_MaxRetries(t *testing.T) {
+	t.Parallel()
+
+	var puts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			w.Write([]byte(`{"_id":"counter","_rev":"1-a","count":1}`))
+			return
+		}
+		atomic.AddInt32(&puts, 1)
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"error":"conflict","reason":"Document update conflict."}`))
+	}))
+	defer srv.Close()
+	db := (&Client{Host: srv.URL, client: &http.Client{}}).Database("update-test")
+
+	for _, tc := range []struct {
+		retries int
+		puts    int32
+	}{
+		{-1, 1},
+		{0, DefaultUpdateRetries + 1},
+		{2, 3},
+	} {
+		atomic.StoreInt32(&puts, 0)
+		var doc counterDoc
+		_, err := db.Update(context.Background(), "counter", &doc, func(interface{}) error {
+			doc.Count++
+			return nil
+		}, UpdateOpts{MaxRetries: tc.retries})
+		if !errors.Is(err, ErrConflict) {
+			t.Fatalf("MaxRetries %d: expected ErrConflict, got %v", tc.retries, err)
+		}
+		if n := atomic.LoadInt32(&puts); n != tc.puts {
+			t.Fatalf("MaxRetries %d: expected %d attempts, got %d", tc.retries, tc.puts, n)
+		}
+	}
+}
@@ -1,8 +1,13 @@
 package couchdb
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
 )
 
 // Results is a struct meant to be embedded in a couchdb request struct with correct
@@ -19,18 +24,269 @@ type Results struct {
 
 // View defines map & reduce functions for a single view
 type View struct {
-	MapFn    string `json:"map,omitempty"`
+	MapFn    string `json:"-"`
 	ReduceFn string `json:"reduce,omitempty"`
+	// Map defines the index of Mango views, used instead of MapFn with language query, e.g.
+	// map[string]interface{}{"fields": map[string]string{"name": "asc"}}
+	Map interface{} `json:"-"`
+	// Options are passed to the view server, e.g. the definition of Mango views
+	Options map[string]interface{} `json:"options,omitempty"`
 }
 
-// DesignDocument describes a language and all associated views
+type viewJSON struct {
+	Map      interface{}            `json:"map,omitempty"`
+	ReduceFn string                 `json:"reduce,omitempty"`
+	Options  map[string]interface{} `json:"options,omitempty"`
+}
+
+// MarshalJSON encodes map either as function or as Mango index definition
+func (v View) MarshalJSON() ([]byte, error) {
+	out := viewJSON{Map: v.Map, ReduceFn: v.ReduceFn, Options: v.Options}
+	if v.MapFn != "" {
+		out.Map = v.MapFn
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes map functions into MapFn and all other maps into Map
+func (v *View) UnmarshalJSON(bs []byte) error {
+	in := viewJSON{}
+	if err := json.Unmarshal(bs, &in); err != nil {
+		return err
+	}
+	*v = View{ReduceFn: in.ReduceFn, Options: in.Options}
+	if fn, ok := in.Map.(string); ok {
+		v.MapFn = fn
+	} else {
+		v.Map = in.Map
+	}
+	return nil
+}
+
+// LanguageQuery is the language of design documents containing Mango views
+const LanguageQuery = "query"
+
+// DesignOptions configures the indexing of a design document
+type DesignOptions struct {
+	// Partitioned makes views of partitioned databases global if set to false
+	Partitioned *bool `json:"partitioned,omitempty"`
+	// LocalSeq includes the local sequence of documents in map functions as doc._local_seq
+	LocalSeq bool `json:"local_seq,omitempty"`
+	// IncludeDesign passes design documents to map functions
+	IncludeDesign bool `json:"include_design,omitempty"`
+}
+
+// Rewrite is a single rule of the rewrites of a design document
+type Rewrite struct {
+	From   string                 `json:"from"`
+	To     string                 `json:"to"`
+	Method string                 `json:"method,omitempty"`
+	Query  map[string]interface{} `json:"query,omitempty"`
+}
+
+// DesignDocument describes a language and all associated views and functions. Functions are
+// given as source code, keyed by their name.
 type DesignDocument struct {
 	Document
 	Language string          `json:"language"`
-	Views    map[string]View `json:"views"`
+	Views    map[string]View `json:"views,omitempty"`
+	// Filters filter changes feeds and replications
+	Filters map[string]string `json:"filters,omitempty"`
+	// ValidateDocUpdate is called to validate every document written
+	ValidateDocUpdate string `json:"validate_doc_update,omitempty"`
+	// Updates are update handlers, called using CallUpdate
+	Updates map[string]string `json:"updates,omitempty"`
+	// Shows render documents, called using CallShow
+	Shows map[string]string `json:"shows,omitempty"`
+	// Lists render view results, called using CallList
+	Lists map[string]string `json:"lists,omitempty"`
+	// Rewrites is either a []Rewrite or the source code of a rewrite function
+	Rewrites interface{}    `json:"rewrites,omitempty"`
+	Options  *DesignOptions `json:"options,omitempty"`
+	// Autoupdate set to false stops couchdb from building views in the background
+	Autoupdate *bool `json:"autoupdate,omitempty"`
+}
+
+// Values of ViewOpts.Update
+const (
+	// UpdateTrue updates the view before responding, the default
+	UpdateTrue = "true"
+	// UpdateFalse responds with the current view contents without updating it
+	UpdateFalse = "false"
+	// UpdateLazy responds with the current view contents, updating the view afterwards
+	UpdateLazy = "lazy"
+)
+
+// ViewOpts defines parameters for querying views and _all_docs. Keys may be of any JSON type,
+// nil keys are not sent. Options defaulting to true in couchdb are pointers.
+type ViewOpts struct {
+	Key interface{}
+	// Keys restricts results to the given keys, which are sent using POST
+	Keys          []interface{}
+	StartKey      interface{}
+	EndKey        interface{}
+	StartKeyDocID string
+	EndKeyDocID   string
+	Skip          int
+	// Limit caps the number of rows returned; 0 returns all rows
+	Limit        int
+	Descending   bool
+	InclusiveEnd *bool
+	IncludeDocs  bool
+	Group        bool
+	GroupLevel   int
+	Reduce       *bool
+	// Update is one of UpdateTrue, UpdateFalse or UpdateLazy
+	Update      string
+	Stable      bool
+	Conflicts   bool
+	Attachments bool
+	Sorted      *bool
+	UpdateSeq   bool
+}
+
+// params returns all options set, keeping their JSON types
+func (o ViewOpts) params() map[string]interface{} {
+	params := map[string]interface{}{}
+	set := func(name string, value interface{}, ok bool) {
+		if ok {
+			params[name] = value
+		}
+	}
+	set("key", o.Key, o.Key != nil)
+	set("startkey", o.StartKey, o.StartKey != nil)
+	set("endkey", o.EndKey, o.EndKey != nil)
+	set("startkey_docid", o.StartKeyDocID, o.StartKeyDocID != "")
+	set("endkey_docid", o.EndKeyDocID, o.EndKeyDocID != "")
+	set("skip", o.Skip, o.Skip != 0)
+	set("limit", o.Limit, o.Limit != 0)
+	set("descending", o.Descending, o.Descending)
+	set("include_docs", o.IncludeDocs, o.IncludeDocs)
+	set("group", o.Group, o.Group)
+	set("group_level", o.GroupLevel, o.GroupLevel != 0)
+	set("update", o.Update, o.Update != "")
+	set("stable", o.Stable, o.Stable)
+	set("conflicts", o.Conflicts, o.Conflicts)
+	set("attachments", o.Attachments, o.Attachments)
+	set("update_seq", o.UpdateSeq, o.UpdateSeq)
+	if o.InclusiveEnd != nil {
+		params["inclusive_end"] = *o.InclusiveEnd
+	}
+	if o.Reduce != nil {
+		params["reduce"] = *o.Reduce
+	}
+	if o.Sorted != nil {
+		params["sorted"] = *o.Sorted
+	}
+	return params
+}
+
+// values encodes the options as query parameters. Keys are JSON encoded, as expected by couchdb.
+func (o ViewOpts) values() (url.Values, error) {
+	values := url.Values{}
+	for name, value := range o.params() {
+		switch v := value.(type) {
+		case string:
+			if name == "key" || name == "startkey" || name == "endkey" {
+				bs, err := json.Marshal(v)
+				if err != nil {
+					return nil, err
+				}
+				v = string(bs)
+			}
+			values.Set(name, v)
+		case bool:
+			values.Set(name, strconv.FormatBool(v))
+		case int:
+			values.Set(name, strconv.Itoa(v))
+		default:
+			bs, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			values.Set(name, string(bs))
+		}
+	}
+	return values, nil
 }
 
 // Results executes a request against a couchdb view
-func (d *Database) Results(ctx context.Context, design, view string, opts AllDocOpts, results interface{}) error {
+func (d *Database) Results(ctx context.Context, design, view string, opts ViewOpts, results interface{}) error {
 	return d.bulkGet(ctx, fmt.Sprintf("/_design/%s/_view/%s", design, view), opts, results)
 }
+
+// ViewQuery is a single query of a multi-query request, decoding its response into Results
+type ViewQuery struct {
+	Opts    ViewOpts
+	Results interface{}
+}
+
+type viewQueriesResponse struct {
+	Results []json.RawMessage `json:"results"`
+}
+
+// ViewQueries executes several queries against a view in a single request, decoding the
+// response of every query into its Results. Requires couchdb 2.2 or later.
+// POST /{db}/_design/{design}/_view/{view}/queries
+//
+//   var managers, interns employeeResults
+//   err := db.ViewQueries(ctx, "company", "employees", []couchdb.ViewQuery{
+//     {Opts: couchdb.ViewOpts{Key: "manager"}, Results: &managers},
+//     {Opts: couchdb.ViewOpts{Key: "intern", Limit: 10}, Results: &interns},
+//   })
+func (d *Database) ViewQueries(ctx context.Context, design, view string, queries []ViewQuery) error {
+	return d.queries(ctx, fmt.Sprintf("/_design/%s/_view/%s/queries", design, view), queries)
+}
+
+// AllDocsQueries executes several queries against _all_docs in a single request, decoding the
+// response of every query into its Results. Requires couchdb 2.2 or later. POST /{db}/_all_docs/queries
+func (d *Database) AllDocsQueries(ctx context.Context, queries []ViewQuery) error {
+	return d.queries(ctx, "/_all_docs/queries", queries)
+}
+
+func (d *Database) queries(ctx context.Context, path string, queries []ViewQuery) error {
+	body := struct {
+		Queries []map[string]interface{} `json:"queries"`
+	}{}
+	for _, query := range queries {
+		params := query.Opts.params()
+		if query.Opts.Keys != nil {
+			params["keys"] = query.Opts.Keys
+		}
+		body.Queries = append(body.Queries, params)
+	}
+	bs, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", path, bytes.NewReader(bs))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	resp, err := d.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return newError(req, resp, nil)
+	}
+
+	results := viewQueriesResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return err
+	}
+	if len(results.Results) != len(queries) {
+		return fmt.Errorf("couchdb: expected %d query results, got %d", len(queries), len(results.Results))
+	}
+	for i, query := range queries {
+		if query.Results == nil {
+			continue
+		}
+		if err := json.Unmarshal(results.Results[i], query.Results); err != nil {
+			return err
+		}
+	}
+	return nil
+}
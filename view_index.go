@@ -0,0 +1,258 @@
+package couchdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ViewIndexSizes reports the size of a view index in bytes
+type ViewIndexSizes struct {
+	Active   int64 `json:"active"`
+	External int64 `json:"external"`
+	File     int64 `json:"file"`
+}
+
+// ViewIndexInfo describes the state of the views of a design document
+type ViewIndexInfo struct {
+	Language       string         `json:"language"`
+	Signature      string         `json:"signature"`
+	Sizes          ViewIndexSizes `json:"sizes"`
+	UpdateSeq      Sequence       `json:"update_seq"`
+	PurgeSeq       Sequence       `json:"purge_seq"`
+	UpdaterRunning bool           `json:"updater_running"`
+	CompactRunning bool           `json:"compact_running"`
+	WaitingClients int            `json:"waiting_clients"`
+	WaitingCommit  bool           `json:"waiting_commit"`
+}
+
+// DesignInfo contains information about a design document and its views
+type DesignInfo struct {
+	Name      string        `json:"name"`
+	ViewIndex ViewIndexInfo `json:"view_index"`
+}
+
+// DesignInfo fetches the index status of a design document. GET /{db}/_design/{design}/_info
+func (d *Database) DesignInfo(ctx context.Context, design string) (*DesignInfo, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("/_design/%s/_info", design), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	resp, err := d.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newError(req, resp, nil)
+	}
+	info := &DesignInfo{}
+	return info, json.NewDecoder(resp.Body).Decode(info)
+}
+
+// ViewCleanup removes index files no longer used by any design document. POST /{db}/_view_cleanup
+func (d *Database) ViewCleanup(ctx context.Context) error {
+	return d.post(ctx, "/_view_cleanup")
+}
+
+// CompactDesign starts compacting the views of a design document. POST /{db}/_compact/{design}
+func (d *Database) CompactDesign(ctx context.Context, design string) error {
+	return d.post(ctx, fmt.Sprintf("/_compact/%s", design))
+}
+
+// post sends an empty POST request for maintenance tasks, which couchdb accepts asynchronously
+func (d *Database) post(ctx context.Context, path string) error {
+	req, err := http.NewRequest("POST", path, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	resp, err := d.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		return newError(req, resp, nil)
+	}
+	return nil
+}
+
+// Types of active tasks
+const (
+	TaskIndexer            = "indexer"
+	TaskReplication        = "replication"
+	TaskDatabaseCompaction = "database_compaction"
+	TaskViewCompaction     = "view_compaction"
+)
+
+// ActiveTask describes a task running on a couchdb node
+type ActiveTask struct {
+	Type string `json:"type"`
+	Node string `json:"node"`
+	PID  string `json:"pid"`
+	// Database is the database, or with couchdb 2.x the database shard, the task works on
+	Database       string `json:"database"`
+	DesignDocument string `json:"design_document"`
+	Progress       int    `json:"progress"`
+	ChangesDone    int    `json:"changes_done"`
+	TotalChanges   int    `json:"total_changes"`
+	StartedOn      int64  `json:"started_on"`
+	UpdatedOn      int64  `json:"updated_on"`
+}
+
+// ActiveTasks lists the tasks running on the server. GET /_active_tasks
+func (c *Client) ActiveTasks(ctx context.Context) ([]ActiveTask, error) {
+	req, err := http.NewRequest("GET", "/_active_tasks", nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newError(req, resp, nil)
+	}
+	tasks := []ActiveTask{}
+	return tasks, json.NewDecoder(resp.Body).Decode(&tasks)
+}
+
+// IndexProgress reports the progress of building the views of a design document, summed up
+// over all shards
+type IndexProgress struct {
+	ChangesDone  int
+	TotalChanges int
+}
+
+// Percent returns the progress in percent
+func (p IndexProgress) Percent() int {
+	if p.TotalChanges == 0 {
+		return 0
+	}
+	return p.ChangesDone * 100 / p.TotalChanges
+}
+
+// warmUpInterval is the delay between two polls of active tasks during WarmUp
+var warmUpInterval = time.Second
+
+// WarmUp builds the views of a design document, returning once they are up to date. While
+// couchdb is indexing, progress is called with the state of the indexer tasks. Use the context
+// to bound the time spent waiting.
+//
+//   ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+//   defer cancel()
+//   err := db.WarmUp(ctx, "company", func(p couchdb.IndexProgress) {
+//     log.Printf("indexing: %d%%", p.Percent())
+//   })
+func (d *Database) WarmUp(ctx context.Context, design string, progress func(IndexProgress)) error {
+	ddoc := DesignDocument{}
+	if err := d.Get(ctx, designPrefix+design, &ddoc); err != nil {
+		return err
+	}
+	if len(ddoc.Views) == 0 {
+		return nil
+	}
+	// all views of a design document share an index, so querying one builds all of them
+	views := []string{}
+	for view := range ddoc.Views {
+		views = append(views, view)
+	}
+	sort.Strings(views)
+
+	ticker := time.NewTicker(warmUpInterval)
+	defer ticker.Stop()
+	for {
+		done := make(chan error, 1)
+		go func() {
+			done <- d.triggerIndex(ctx, design, views[0])
+		}()
+
+	poll:
+		for {
+			select {
+			case err := <-done:
+				var apiErr *Error
+				if err == nil || ctx.Err() != nil || errors.As(err, &apiErr) && apiErr.StatusCode < http.StatusInternalServerError {
+					return err
+				}
+				// the query timed out or failed while indexing continues, query again once polled
+				break poll
+			case <-ticker.C:
+				if progress == nil {
+					continue
+				}
+				p, err := d.indexProgress(ctx, design)
+				if err != nil {
+					continue
+				}
+				progress(p)
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// triggerIndex queries a view without returning rows, which returns once the index is built
+func (d *Database) triggerIndex(ctx context.Context, design, view string) error {
+	req, err := d.viewRequest(ctx, fmt.Sprintf("/_design/%s/_view/%s", design, view), ViewOpts{Update: UpdateTrue})
+	if err != nil {
+		return err
+	}
+	values := req.URL.Query()
+	values.Set("limit", "0")
+	req.URL.RawQuery = values.Encode()
+	resp, err := d.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return newError(req, resp, nil)
+	}
+	return nil
+}
+
+// indexProgress sums up the indexer tasks of the design document
+func (d *Database) indexProgress(ctx context.Context, design string) (IndexProgress, error) {
+	tasks, err := d.c.ActiveTasks(ctx)
+	if err != nil {
+		return IndexProgress{}, err
+	}
+	p := IndexProgress{}
+	for _, task := range tasks {
+		if task.Type != TaskIndexer || task.DesignDocument != designPrefix+design || !taskOf(task, d.Name) {
+			continue
+		}
+		p.ChangesDone += task.ChangesDone
+		p.TotalChanges += task.TotalChanges
+	}
+	return p, nil
+}
+
+// taskOf reports whether a task works on the database or, with couchdb 2.x, one of its shards
+// named shards/{range}/{db}.{suffix}
+func taskOf(task ActiveTask, db string) bool {
+	parts := strings.SplitN(task.Database, "/", 3)
+	if len(parts) != 3 || parts[0] != "shards" {
+		return task.Database == db
+	}
+	shard := parts[2]
+	if i := strings.LastIndex(shard, "."); i >= 0 {
+		shard = shard[:i]
+	}
+	return shard == db
+}
@@ -0,0 +1,54 @@
+// +build !integration
+
+package couchdb
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDatabase_WarmUp(t *testing.T) {
+	t.Parallel()
+
+	db := client.Database("warmup-test")
+	client.Databases.Create(db.Name, DatabaseClusterOptions{})
+	defer client.Databases.Delete(db.Name)
+
+	for _, name := range []string{"Michael", "Raphael", "Yumi"} {
+		if _, err := db.Put(context.Background(), "employee:"+name, testDoc{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.DeployDesign(context.Background(), []DesignDocument{{
+		Document: Document{ID: "company"},
+		Views: map[string]View{
+			"names": {MapFn: "function(doc) { emit(doc.name) }"},
+		},
+	}}, DeployOpts{}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := db.WarmUp(ctx, "company", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := db.DesignInfo(context.Background(), "company")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "company" || info.ViewIndex.Signature == "" {
+		t.Fatalf("Unexpected design info %#v", info)
+	}
+	if err := db.CompactDesign(context.Background(), "company"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.ViewCleanup(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ActiveTasks(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -66,4 +66,96 @@ function(doc) {
 			}
 		}
 	})
+
+	t.Run("keys", func(t *testing.T) {
+		var result = employeeResults{}
+		if err := playground.Results(context.Background(), "company", "employees", ViewOpts{
+			Keys: []interface{}{"employee:michael", "employee:unknown"},
+		}, &result); err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Employees) != 1 || result.Employees[0].Value.ID != "employee:michael" {
+			t.Fatalf("Expected only employee:michael, got %#v", result.Employees)
+		}
+	})
+
+	t.Run("descending", func(t *testing.T) {
+		var result = employeeResults{}
+		if err := playground.Results(context.Background(), "company", "employees", ViewOpts{
+			Descending: true,
+			Limit:      1,
+		}, &result); err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Employees) != 1 || result.Employees[0].Value.ID != "employee:raphael" {
+			t.Fatalf("Expected only employee:raphael, got %#v", result.Employees)
+		}
+	})
+
+	t.Run("rows", func(t *testing.T) {
+		rows, err := playground.ViewRows(context.Background(), "company", "employees", ViewOpts{
+			IncludeDocs: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+
+		ids := []string{}
+		for rows.Next() {
+			var key string
+			if err := rows.ScanKey(&key); err != nil {
+				t.Fatal(err)
+			}
+			var doc testDoc
+			if err := rows.ScanDoc(&doc); err != nil {
+				t.Fatal(err)
+			}
+			if key != rows.ID() || doc.Name == "" {
+				t.Fatalf("Unexpected row %q: %#v", key, doc)
+			}
+			ids = append(ids, rows.ID())
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if len(ids) != 2 || rows.TotalRows() != 2 {
+			t.Fatalf("Expected 2 rows, got %v of %d", ids, rows.TotalRows())
+		}
+	})
+
+	t.Run("queries", func(t *testing.T) {
+		if !client.CouchDB.HasClusterSupport() {
+			t.Skip("multi-query requests require couchdb 2.2")
+		}
+		var michael, all employeeResults
+		if err := playground.ViewQueries(context.Background(), "company", "employees", []ViewQuery{
+			{Opts: ViewOpts{Key: "employee:michael"}, Results: &michael},
+			{Opts: ViewOpts{}, Results: &all},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if len(michael.Employees) != 1 || len(all.Employees) != 2 {
+			t.Fatalf("Expected 1 and 2 results, got %d and %d", len(michael.Employees), len(all.Employees))
+		}
+	})
+}
+
+func TestViewOpts_values(t *testing.T) {
+	inclusiveEnd := false
+	values, err := ViewOpts{
+		StartKey:      []interface{}{"a", 1},
+		EndKey:        "b",
+		StartKeyDocID: "doc",
+		InclusiveEnd:  &inclusiveEnd,
+		GroupLevel:    2,
+		Update:        UpdateLazy,
+	}.values()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "endkey=%22b%22&group_level=2&inclusive_end=false&startkey=%5B%22a%22%2C1%5D&startkey_docid=doc&update=lazy"
+	if values.Encode() != expected {
+		t.Fatalf("Expected %q, got %q", expected, values.Encode())
+	}
 }